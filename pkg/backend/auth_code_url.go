@@ -0,0 +1,139 @@
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/persist"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+)
+
+func authCodeStorageKey(state string) string { return "auth-code/" + state }
+
+func (b *backend) pathAuthCodeURL() *framework.Path {
+	return &framework.Path{
+		Pattern: "auth-code-url",
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the credential this flow will populate once the resulting code is redeemed at self/:name.",
+			},
+			"redirect_url": {
+				Type:        framework.TypeString,
+				Description: "redirect_uri to send with the authorization request; must be repeated on the token request.",
+			},
+			"scopes": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Scopes to request.",
+			},
+			"pkce": {
+				Type:        framework.TypeBool,
+				Description: "Generate and persist an RFC 7636 PKCE verifier for this flow, sending its S256 challenge with the authorization request.",
+			},
+			"nonce": {
+				Type:        framework.TypeString,
+				Description: "OIDC nonce to bind the resulting id_token to, if the provider is OIDC-aware.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathAuthCodeURLWrite,
+		},
+		HelpSynopsis:    "Generate an authorization URL to redirect a user to.",
+		HelpDescription: "Generates a random state value, persists it (along with the redirect_url, PKCE verifier, and nonce this flow used) under auth-code/:state, and returns the authorization URL along with that state. Redeem the resulting code at self/:name, passing both code and state back.",
+	}
+}
+
+func (b *backend) pathAuthCodeURLWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if name == "" {
+		return logical.ErrorResponse("name is required"), nil
+	}
+
+	ops, err := b.privateOps(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+
+	authCode := &persist.AuthCodeEntry{
+		Name:        name,
+		RedirectURL: data.Get("redirect_url").(string),
+		Nonce:       data.Get("nonce").(string),
+		IssuedAt:    time.Now(),
+	}
+
+	var urlOpts []provider.AuthCodeURLOption
+	if authCode.RedirectURL != "" {
+		urlOpts = append(urlOpts, provider.WithRedirectURL(authCode.RedirectURL))
+	}
+	if scopes := data.Get("scopes").([]string); len(scopes) > 0 {
+		urlOpts = append(urlOpts, provider.WithScopes(scopes))
+	}
+	if authCode.Nonce != "" {
+		urlOpts = append(urlOpts, provider.WithNonce(authCode.Nonce))
+	}
+	if data.Get("pkce").(bool) {
+		verifier, err := provider.GeneratePKCEVerifier()
+		if err != nil {
+			return nil, err
+		}
+		challenge, err := provider.NewPKCEChallenge(verifier, "")
+		if err != nil {
+			return nil, err
+		}
+		authCode.PKCEVerifier = verifier
+		urlOpts = append(urlOpts, challenge)
+	}
+
+	authCodeURL, ok := ops.AuthCodeURL(state, urlOpts...)
+	if !ok {
+		return logical.ErrorResponse("provider does not support generating an authorization URL for this request (e.g. it requires pushed authorization requests)"), nil
+	}
+
+	entry, err := logical.StorageEntryJSON(authCodeStorageKey(state), authCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"state":         state,
+			"auth_code_url": authCodeURL,
+		},
+	}, nil
+}
+
+func (b *backend) authCode(ctx context.Context, storage logical.Storage, state string) (*persist.AuthCodeEntry, error) {
+	raw, err := storage.Get(ctx, authCodeStorageKey(state))
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	entry := &persist.AuthCodeEntry{}
+	if err := raw.DecodeJSON(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
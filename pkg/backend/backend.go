@@ -0,0 +1,53 @@
+// Package backend implements the Vault logical backend that exposes the
+// pkg/provider OAuth 2.0 client as a secrets engine mount: a config path
+// selecting and configuring a provider, and a set of self/:name paths that
+// bootstrap, read, and use credentials built from it.
+//
+// This backend is deliberately narrow: it covers exactly the operations the
+// provider package's grants need a durable home for (credential storage,
+// authorization code callbacks, token exchange, the device grant, and DPoP
+// proof issuance). It does not implement Vault leases on the tokens it
+// stores, periodic cleanup of expired auth-code/:state entries, or a
+// Transit-backed private_key_jwt signer; provider.JWTSigner already defines
+// the extension point a Transit-backed signer would implement, but wiring
+// it to this backend's own api.Client is left for when an operator actually
+// asks for it.
+package backend
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Factory returns a Vault logical.Factory for this backend, the entry point
+// a plugin main package registers with the Vault SDK.
+func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+	b := newBackend()
+	if err := b.Setup(ctx, conf); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// backend holds the framework.Backend this package builds, embedded so that
+// path handlers can be declared as its methods.
+type backend struct {
+	*framework.Backend
+}
+
+func newBackend() *backend {
+	b := &backend{}
+
+	b.Backend = &framework.Backend{
+		Help:        "The OAuth 2.0 secrets engine issues and refreshes tokens from a configured OAuth 2.0 or OpenID Connect provider.",
+		BackendType: logical.TypeLogical,
+		Paths: framework.PathAppend(
+			[]*framework.Path{b.pathConfig()},
+			b.pathsCreds(),
+		),
+	}
+
+	return b
+}
@@ -0,0 +1,443 @@
+package backend_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/backend"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// registerTestProvider registers a "backend-test" provider, pointed at fake
+// URLs a test's MockRoundTripper intercepts, exactly once for the whole test
+// binary: provider.GlobalRegistry panics on a duplicate name, and every test
+// in this file shares it.
+var registerTestProvider = sync.OnceFunc(func() {
+	provider.GlobalRegistry.MustRegister("backend-test", provider.OIDCAwareFactory(provider.BasicFactory(provider.Endpoint{
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "http://localhost/authorize",
+			TokenURL: "http://localhost/token",
+		},
+		DeviceAuthURL: "http://localhost/device",
+	})))
+})
+
+func newTestBackend(t *testing.T, h http.Handler) (logical.Backend, logical.Storage, context.Context) {
+	t.Helper()
+
+	registerTestProvider()
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+		Transport: &testutil.MockRoundTripper{Handler: h},
+	})
+
+	conf := logical.TestBackendConfig()
+	conf.StorageView = &logical.InmemStorage{}
+
+	b, err := backend.Factory(ctx, conf)
+	require.NoError(t, err)
+
+	return b, conf.StorageView, ctx
+}
+
+func configureTestBackend(t *testing.T, b logical.Backend, storage logical.Storage, ctx context.Context) {
+	t.Helper()
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"provider":      "backend-test",
+			"client_id":     "foo",
+			"client_secret": "bar",
+		},
+	})
+	require.NoError(t, err)
+	require.Falsef(t, resp.IsError(), "config write returned an error: %v", resp)
+}
+
+func TestBackendClientCredentialsCredential(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		data, err := url.ParseQuery(string(b))
+		require.NoError(t, err)
+		require.Equal(t, "client_credentials", data.Get("grant_type"))
+
+		fmt.Fprint(w, `{"access_token":"abcd","token_type":"bearer","expires_in":3600}`)
+	})
+
+	b, storage, ctx := newTestBackend(t, h)
+	configureTestBackend(t, b, storage, ctx)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "self/my-cred",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+	require.Equal(t, "abcd", resp.Data["access_token"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "self/my-cred",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	require.Equal(t, "abcd", resp.Data["access_token"])
+}
+
+func TestBackendTokenExchange(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		data, err := url.ParseQuery(string(b))
+		require.NoError(t, err)
+		require.Equal(t, "urn:ietf:params:oauth:grant-type:token-exchange", data.Get("grant_type"))
+		require.Equal(t, "inline-subject-token", data.Get("subject_token"))
+		require.Equal(t, provider.TokenTypeAccessToken, data.Get("subject_token_type"))
+
+		fmt.Fprint(w, `{"access_token":"exchanged","token_type":"bearer","expires_in":3600,"issued_token_type":"urn:ietf:params:oauth:token-type:access_token"}`)
+	})
+
+	b, storage, ctx := newTestBackend(t, h)
+	configureTestBackend(t, b, storage, ctx)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "self/exchanged-cred/exchange",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"subject_token": "inline-subject-token",
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+	require.Equal(t, "exchanged", resp.Data["access_token"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "self/exchanged-cred",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "exchanged", resp.Data["access_token"])
+}
+
+func TestBackendTokenExchangeFromStoredCredential(t *testing.T) {
+	attempt := 0
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		data, err := url.ParseQuery(string(b))
+		require.NoError(t, err)
+
+		switch attempt {
+		case 1:
+			require.Equal(t, "client_credentials", data.Get("grant_type"))
+			fmt.Fprint(w, `{"access_token":"source-token","token_type":"bearer","expires_in":3600}`)
+		case 2:
+			require.Equal(t, "source-token", data.Get("subject_token"))
+			fmt.Fprint(w, `{"access_token":"exchanged-from-cred","token_type":"bearer","expires_in":3600}`)
+		default:
+			t.Fatalf("unexpected token request attempt %d", attempt)
+		}
+	})
+
+	b, storage, ctx := newTestBackend(t, h)
+	configureTestBackend(t, b, storage, ctx)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "self/source-cred",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "self/exchanged-cred/exchange",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"subject_token_credential": "source-cred",
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+	require.Equal(t, "exchanged-from-cred", resp.Data["access_token"])
+}
+
+func TestBackendAuthStyleConfigurable(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		require.True(t, ok, "expected HTTP Basic credentials, got none")
+		require.Equal(t, "foo", user)
+		require.Equal(t, "bar", pass)
+
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		data, err := url.ParseQuery(string(b))
+		require.NoError(t, err)
+		require.Empty(t, data.Get("client_secret"), "client_secret must not also be sent in the body under client_secret_basic")
+
+		fmt.Fprint(w, `{"access_token":"basic-auth-token","token_type":"bearer","expires_in":3600}`)
+	})
+
+	b, storage, ctx := newTestBackend(t, h)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"provider":      "backend-test",
+			"client_id":     "foo",
+			"client_secret": "bar",
+			"provider_options": map[string]interface{}{
+				"auth_style": provider.AuthStyleClientSecretBasic,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "self/basic-auth-cred",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+	require.Equal(t, "basic-auth-token", resp.Data["access_token"])
+}
+
+func TestBackendConfigRedactsSigningKey(t *testing.T) {
+	b, storage, ctx := newTestBackend(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"provider":      "backend-test",
+			"client_id":     "foo",
+			"client_secret": "bar",
+			"provider_options": map[string]interface{}{
+				"auth_style": "client_secret_post",
+				"tenant":     "some-tenant",
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "config",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, resp.Data, "client_secret")
+
+	opts := resp.Data["provider_options"].(map[string]string)
+	require.Equal(t, "some-tenant", opts["tenant"])
+	require.NotContains(t, opts, "jwt_signing_key")
+}
+
+func TestBackendDeviceGrant(t *testing.T) {
+	poll := 0
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/device":
+			fmt.Fprint(w, `{"device_code":"devcode-123","user_code":"ABCD-EFGH","verification_uri":"http://example.com/device","interval":1,"expires_in":600}`)
+		case "/token":
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			data, err := url.ParseQuery(string(b))
+			require.NoError(t, err)
+			require.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", data.Get("grant_type"))
+			require.Equal(t, "devcode-123", data.Get("device_code"))
+
+			poll++
+			if poll == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"authorization_pending"}`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"device-token","token_type":"bearer","expires_in":3600}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	b, storage, ctx := newTestBackend(t, h)
+	configureTestBackend(t, b, storage, ctx)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "self/device-cred/device",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+	require.Equal(t, "devcode-123", resp.Data["device_code"])
+	require.Equal(t, "ABCD-EFGH", resp.Data["user_code"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "self/device-cred/device",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"device_code": "devcode-123",
+			"interval":    "1",
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+	require.Equal(t, "device-token", resp.Data["access_token"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "self/device-cred",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "device-token", resp.Data["access_token"])
+}
+
+func TestBackendDPoPProof(t *testing.T) {
+	var sawDPoPHeader bool
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("DPoP") != "" {
+			sawDPoPHeader = true
+		}
+		fmt.Fprint(w, `{"access_token":"dpop-bound","token_type":"bearer","expires_in":3600}`)
+	})
+
+	b, storage, ctx := newTestBackend(t, h)
+	configureTestBackend(t, b, storage, ctx)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "self/dpop-cred",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"dpop": true,
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+	require.True(t, sawDPoPHeader, "expected a DPoP proof header on the token request")
+	require.Equal(t, "dpop-bound", resp.Data["access_token"])
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "self/dpop-cred/dpop-proof",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"method": "GET",
+			"url":    "https://api.example.com/resource",
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+	require.NotEmpty(t, resp.Data["proof"])
+}
+
+func TestBackendDPoPProofWithoutKey(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token":"no-dpop","token_type":"bearer","expires_in":3600}`)
+	})
+
+	b, storage, ctx := newTestBackend(t, h)
+	configureTestBackend(t, b, storage, ctx)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "self/plain-cred",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "self/plain-cred/dpop-proof",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"method": "GET",
+			"url":    "https://api.example.com/resource",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+}
+
+func TestBackendAuthCodeURLFlow(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			data, err := url.ParseQuery(string(b))
+			require.NoError(t, err)
+			require.Equal(t, "123456", data.Get("code"))
+			require.NotEmpty(t, data.Get("code_verifier"))
+
+			fmt.Fprint(w, `{"access_token":"from-code","token_type":"bearer","expires_in":3600}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	b, storage, ctx := newTestBackend(t, h)
+	configureTestBackend(t, b, storage, ctx)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "auth-code-url",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"name":         "pkce-cred",
+			"redirect_url": "http://example.com/redirect",
+			"pkce":         true,
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+
+	state, _ := resp.Data["state"].(string)
+	require.NotEmpty(t, state)
+	require.Contains(t, resp.Data["auth_code_url"], "code_challenge=")
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "self/pkce-cred",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"code":  "123456",
+			"state": state,
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError())
+	require.Equal(t, "from-code", resp.Data["access_token"])
+}
@@ -0,0 +1,165 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+)
+
+const configStorageKey = "config"
+
+// configEntry is the mount-wide configuration persisted under "config". It
+// selects a provider.Factory from provider.GlobalRegistry by name and
+// supplies the client credentials and provider options (including
+// auth_style and its related jwt_signing_key/jwt_signing_algorithm/
+// jwt_audience options) every credential under this mount shares.
+type configEntry struct {
+	Provider        string            `json:"provider"`
+	ProviderOptions map[string]string `json:"provider_options,omitempty"`
+	ClientID        string            `json:"client_id"`
+	ClientSecret    string            `json:"client_secret"`
+}
+
+func (b *backend) pathConfig() *framework.Path {
+	return &framework.Path{
+		Pattern: "config",
+		Fields: map[string]*framework.FieldSchema{
+			"provider": {
+				Type:        framework.TypeString,
+				Description: "Name of the registered provider to use, e.g. \"microsoft_azure_ad\".",
+			},
+			"provider_options": {
+				Type:        framework.TypeMap,
+				Description: "Provider-specific options, e.g. tenant, issuer_url, auth_style, jwt_signing_key.",
+			},
+			"client_id": {
+				Type:        framework.TypeString,
+				Description: "OAuth 2.0 client ID.",
+			},
+			"client_secret": {
+				Type:        framework.TypeString,
+				Description: "OAuth 2.0 client secret.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.pathConfigRead,
+			logical.UpdateOperation: b.pathConfigWrite,
+		},
+		HelpSynopsis:    "Configure the OAuth 2.0 provider this mount uses.",
+		HelpDescription: "Selects a provider by name and supplies the client credentials and provider-specific options (including client authentication style) every credential under this mount shares.",
+	}
+}
+
+func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"provider":         cfg.Provider,
+			"provider_options": redactedProviderOptions(cfg.ProviderOptions),
+			"client_id":        cfg.ClientID,
+		},
+	}, nil
+}
+
+// sensitiveProviderOptions are provider_options keys never echoed back by a
+// config read, for the same reason client_secret isn't: they're secret
+// material, not identifying configuration.
+var sensitiveProviderOptions = map[string]bool{
+	"jwt_signing_key": true,
+}
+
+func redactedProviderOptions(opts map[string]string) map[string]string {
+	if opts == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(opts))
+	for k, v := range opts {
+		if sensitiveProviderOptions[k] {
+			continue
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	cfg := &configEntry{
+		Provider:     data.Get("provider").(string),
+		ClientID:     data.Get("client_id").(string),
+		ClientSecret: data.Get("client_secret").(string),
+	}
+	if cfg.Provider == "" {
+		return logical.ErrorResponse("provider is required"), nil
+	}
+
+	if raw, ok := data.GetOk("provider_options"); ok {
+		opts := make(map[string]string, len(raw.(map[string]interface{})))
+		for k, v := range raw.(map[string]interface{}) {
+			opts[k] = fmt.Sprintf("%v", v)
+		}
+		cfg.ProviderOptions = opts
+	}
+
+	// Constructing the provider here, instead of only at request time,
+	// reports a misconfigured auth_style or provider name when the mount is
+	// configured rather than on the first credential request.
+	if _, err := provider.GlobalRegistry.New(ctx, cfg.Provider, cfg.ProviderOptions); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entry, err := logical.StorageEntryJSON(configStorageKey, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) config(ctx context.Context, storage logical.Storage) (*configEntry, error) {
+	raw, err := storage.Get(ctx, configStorageKey)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	cfg := &configEntry{}
+	if err := raw.DecodeJSON(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// privateOps returns the PrivateOperations for this mount's provider and
+// configured client credentials.
+func (b *backend) privateOps(ctx context.Context, storage logical.Storage) (provider.PrivateOperations, error) {
+	cfg, err := b.config(ctx, storage)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("backend: mount is not configured; write to config first")
+	}
+
+	p, err := provider.GlobalRegistry.New(ctx, cfg.Provider, cfg.ProviderOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Private(cfg.ClientID, cfg.ClientSecret), nil
+}
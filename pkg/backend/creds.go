@@ -0,0 +1,289 @@
+package backend
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/persist"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+	"golang.org/x/oauth2"
+)
+
+func credStorageKey(name string) string { return "creds/" + name }
+
+func (b *backend) pathsCreds() []*framework.Path {
+	return []*framework.Path{
+		b.pathCredsCRUD(),
+		b.pathCredsExchange(),
+		b.pathCredsDevice(),
+		b.pathCredsDPoPProof(),
+		b.pathAuthCodeURL(),
+	}
+}
+
+func (b *backend) pathCredsCRUD() *framework.Path {
+	return &framework.Path{
+		Pattern: "self/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the credential.",
+			},
+			"code": {
+				Type:        framework.TypeString,
+				Description: "Authorization code to redeem, from a prior auth-code-url call. If unset, the client_credentials grant is used instead.",
+			},
+			"state": {
+				Type:        framework.TypeString,
+				Description: "The state value returned alongside code, used to recall the redirect_uri and PKCE verifier auth-code-url persisted for this flow.",
+			},
+			"scopes": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Scopes to send as the scope parameter on the token request.",
+			},
+			"dpop": {
+				Type:        framework.TypeBool,
+				Description: "Generate a DPoP (RFC 9449) key and bind the resulting token to it. The key is persisted and reused on refresh; use self/:name/dpop-proof to sign downstream requests with it.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.pathCredsRead,
+			logical.UpdateOperation: b.pathCredsWrite,
+			logical.DeleteOperation: b.pathCredsDelete,
+		},
+		HelpSynopsis:    "Bootstrap, read, or remove a credential.",
+		HelpDescription: "Writing redeems an authorization code (with state from a prior auth-code-url call) or, if no code is given, performs the client_credentials grant. Reading refreshes the stored token first if it has expired and a refresh token is available.",
+	}
+}
+
+func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	entry, err := b.credential(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	if entry.Token.Valid() {
+		return credResponse(entry), nil
+	}
+	if entry.Token.RefreshToken == "" {
+		return logical.ErrorResponse("credential %q has expired and has no refresh token", name), nil
+	}
+
+	// Only reached once a refresh is actually needed, so a read of an
+	// unexpired credential doesn't pay for constructing the provider (e.g.
+	// an OIDC discovery request) on every call.
+	ops, err := b.privateOps(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.refreshCredential(ctx, ops, entry); err != nil {
+		return nil, fmt.Errorf("backend: refreshing credential %q: %w", name, err)
+	}
+
+	if err := b.putCredential(ctx, req.Storage, entry); err != nil {
+		return nil, err
+	}
+
+	return credResponse(entry), nil
+}
+
+// refreshCredential exchanges entry's refresh token for a new access token
+// in place and, for an OIDC-aware provider, carries forward its last
+// verified ID token claims across a response that didn't rotate the
+// id_token, per provider.OIDCOperations.VerifyIDToken.
+func (b *backend) refreshCredential(ctx context.Context, ops provider.PrivateOperations, entry *persist.CredentialEntry) error {
+	var refreshOpts []provider.TokenRequestOption
+	signer, err := dpopSigner(entry)
+	if err != nil {
+		return err
+	}
+	if signer != nil {
+		refreshOpts = append(refreshOpts, provider.WithDPoPKey(signer))
+	}
+
+	refreshed, err := ops.RefreshToken(ctx, entry.Token, refreshOpts...)
+	if err != nil {
+		return err
+	}
+	entry.Token = refreshed
+
+	if oidcOps, ok := ops.(provider.OIDCOperations); ok {
+		var previous *provider.OIDCClaims
+		if entry.LastVerifiedClaims != nil {
+			previous = &provider.OIDCClaims{Raw: entry.LastVerifiedClaims}
+		}
+
+		claims, err := oidcOps.VerifyIDToken(ctx, refreshed, "", previous)
+		if err != nil {
+			return fmt.Errorf("verifying refreshed id_token: %w", err)
+		}
+		entry.LastVerifiedClaims = claims.Raw
+	}
+
+	return nil
+}
+
+func (b *backend) pathCredsWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	ops, err := b.privateOps(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenOpts []provider.TokenRequestOption
+	if scopes := data.Get("scopes").([]string); len(scopes) > 0 {
+		// WithScopes only applies to an authorization URL; a token request
+		// (the client_credentials grant here) has no equivalent option, so
+		// send scope as a plain request parameter instead.
+		tokenOpts = append(tokenOpts, provider.WithURLParams{"scope": strings.Join(scopes, " ")})
+	}
+
+	var dpopKeyPKCS8 []byte
+	if data.Get("dpop").(bool) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("backend: generating DPoP key: %w", err)
+		}
+
+		dpopKeyPKCS8, err = x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("backend: marshaling DPoP key: %w", err)
+		}
+
+		tokenOpts = append(tokenOpts, provider.WithDPoPKey(key))
+	}
+
+	var token *oauth2.Token
+	var nonce string
+
+	if code := data.Get("code").(string); code != "" {
+		state := data.Get("state").(string)
+		if state == "" {
+			return logical.ErrorResponse("state is required when code is given"), nil
+		}
+
+		authCode, err := b.authCode(ctx, req.Storage, state)
+		if err != nil {
+			return nil, err
+		}
+		if authCode == nil {
+			return logical.ErrorResponse("no pending authorization request for state %q", state), nil
+		}
+
+		exchangeOpts := append([]provider.TokenRequestOption{provider.WithRedirectURL(authCode.RedirectURL)}, tokenOpts...)
+		if authCode.PKCEVerifier != "" {
+			exchangeOpts = append(exchangeOpts, provider.WithPKCEVerifier(authCode.PKCEVerifier))
+		}
+		nonce = authCode.Nonce
+
+		token, err = ops.AuthCodeExchange(ctx, code, exchangeOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := req.Storage.Delete(ctx, authCodeStorageKey(state)); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		token, err = ops.ClientCredentials(ctx, tokenOpts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entry := &persist.CredentialEntry{Name: name, Token: token, IssuedAt: time.Now(), DPoPKeyPKCS8: dpopKeyPKCS8}
+
+	if oidcOps, ok := ops.(provider.OIDCOperations); ok {
+		claims, err := oidcOps.VerifyIDToken(ctx, entry.Token, nonce, nil)
+		if err != nil {
+			return nil, fmt.Errorf("backend: verifying id_token: %w", err)
+		}
+		entry.LastVerifiedClaims = claims.Raw
+	}
+
+	if err := b.putCredential(ctx, req.Storage, entry); err != nil {
+		return nil, err
+	}
+
+	return credResponse(entry), nil
+}
+
+func (b *backend) pathCredsDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	return nil, req.Storage.Delete(ctx, credStorageKey(name))
+}
+
+func (b *backend) credential(ctx context.Context, storage logical.Storage, name string) (*persist.CredentialEntry, error) {
+	raw, err := storage.Get(ctx, credStorageKey(name))
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	entry := &persist.CredentialEntry{}
+	if err := raw.DecodeJSON(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (b *backend) putCredential(ctx context.Context, storage logical.Storage, entry *persist.CredentialEntry) error {
+	entry.UpdatedAt = time.Now()
+
+	se, err := logical.StorageEntryJSON(credStorageKey(entry.Name), entry)
+	if err != nil {
+		return err
+	}
+	return storage.Put(ctx, se)
+}
+
+// dpopSigner parses entry's persisted DPoP key, if any, into a crypto.Signer
+// suitable for provider.WithDPoPKey and provider.GenerateDPoPProof. It
+// returns a nil signer, not an error, for a credential that wasn't
+// bootstrapped with dpop=true.
+func dpopSigner(entry *persist.CredentialEntry) (crypto.Signer, error) {
+	if len(entry.DPoPKeyPKCS8) == 0 {
+		return nil, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(entry.DPoPKeyPKCS8)
+	if err != nil {
+		return nil, fmt.Errorf("backend: parsing persisted DPoP key: %w", err)
+	}
+
+	signer, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("backend: persisted DPoP key is %T, expected *ecdsa.PrivateKey", key)
+	}
+	return signer, nil
+}
+
+func credResponse(entry *persist.CredentialEntry) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"access_token": entry.Token.AccessToken,
+			"token_type":   entry.Token.TokenType,
+			"expiry":       entry.Token.Expiry,
+			"claims":       entry.LastVerifiedClaims,
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/persist"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+)
+
+func (b *backend) pathCredsDevice() *framework.Path {
+	return &framework.Path{
+		Pattern: "self/" + framework.GenericNameRegex("name") + "/device",
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the credential this flow will populate.",
+			},
+			"device_code": {
+				Type:        framework.TypeString,
+				Description: "device_code returned by a prior call with this field unset. When set, this request blocks polling the token endpoint until the grant completes, is denied, or expires.",
+			},
+			"interval": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Polling interval returned alongside device_code. Only meaningful together with device_code.",
+			},
+			"scopes": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Scopes to request. Only meaningful when device_code is unset.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathCredsDeviceWrite,
+		},
+		HelpSynopsis:    "Bootstrap a credential via the RFC 8628 device authorization grant.",
+		HelpDescription: "Called with no device_code, starts a device authorization request and returns the user_code and verification_uri to present to the user. Called again with the returned device_code, blocks polling the token endpoint until the user completes authorization, then stores the result as a credential under self/:name.",
+	}
+}
+
+func (b *backend) pathCredsDeviceWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	ops, err := b.privateOps(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceCode := data.Get("device_code").(string)
+	if deviceCode == "" {
+		var urlOpts []provider.AuthCodeURLOption
+		if scopes := data.Get("scopes").([]string); len(scopes) > 0 {
+			urlOpts = append(urlOpts, provider.WithScopes(scopes))
+		}
+
+		da, err := ops.DeviceAuthorization(ctx, urlOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"device_code":               da.DeviceCode,
+				"user_code":                 da.UserCode,
+				"verification_uri":          da.VerificationURI,
+				"verification_uri_complete": da.VerificationURIComplete,
+				"interval":                  int64(da.Interval / time.Second),
+				"expires_in":                int64(da.ExpiresIn / time.Second),
+			},
+		}, nil
+	}
+
+	interval := time.Duration(data.Get("interval").(int)) * time.Second
+
+	// DevicePollToken has no TokenRequestOption parameter, unlike the
+	// other grants, so a credential bootstrapped this way can't be bound
+	// to a DPoP key the way self/:name and self/:name/exchange can.
+	token, err := ops.DevicePollToken(ctx, deviceCode, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &persist.CredentialEntry{Name: name, Token: token, IssuedAt: time.Now()}
+
+	if oidcOps, ok := ops.(provider.OIDCOperations); ok {
+		if claims, err := oidcOps.VerifyIDToken(ctx, token, "", nil); err == nil {
+			entry.LastVerifiedClaims = claims.Raw
+		}
+		// As with token exchange, a device grant's token response isn't
+		// required to carry an id_token, so a verification error here is
+		// treated as "none to verify" rather than surfaced to the caller.
+	}
+
+	if err := b.putCredential(ctx, req.Storage, entry); err != nil {
+		return nil, fmt.Errorf("backend: storing credential %q: %w", name, err)
+	}
+
+	return credResponse(entry), nil
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+)
+
+func (b *backend) pathCredsDPoPProof() *framework.Path {
+	return &framework.Path{
+		Pattern: "self/" + framework.GenericNameRegex("name") + "/dpop-proof",
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the credential whose DPoP key signs the proof.",
+			},
+			"method": {
+				Type:        framework.TypeString,
+				Description: "HTTP method (htm) of the request the proof is for.",
+			},
+			"url": {
+				Type:        framework.TypeString,
+				Description: "HTTP URL (htu) of the request the proof is for.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathCredsDPoPProofRead,
+		},
+		HelpSynopsis:    "Generate a DPoP proof for a downstream request.",
+		HelpDescription: "Builds a fresh RFC 9449 DPoP proof JWT signed by this credential's persisted DPoP key, for use on a request to method/url that presents this credential's access_token. Errors if the credential wasn't bootstrapped with dpop=true.",
+	}
+}
+
+func (b *backend) pathCredsDPoPProofRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	entry, err := b.credential(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	signer, err := dpopSigner(entry)
+	if err != nil {
+		return nil, err
+	}
+	if signer == nil {
+		return logical.ErrorResponse("credential %q has no DPoP key; bootstrap it with dpop=true to use this endpoint", name), nil
+	}
+
+	method := data.Get("method").(string)
+	url := data.Get("url").(string)
+	if method == "" || url == "" {
+		return logical.ErrorResponse("method and url are required"), nil
+	}
+
+	proof, err := provider.GenerateDPoPProof(signer, method, url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"proof": proof,
+		},
+	}, nil
+}
@@ -0,0 +1,125 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/persist"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+)
+
+func (b *backend) pathCredsExchange() *framework.Path {
+	return &framework.Path{
+		Pattern: "self/" + framework.GenericNameRegex("name") + "/exchange",
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the credential the exchanged token is stored as.",
+			},
+			"subject_token": {
+				Type:        framework.TypeString,
+				Description: "Subject token to exchange. If unset, subject_token_credential's stored access_token is used instead.",
+			},
+			"subject_token_credential": {
+				Type:        framework.TypeString,
+				Description: "Name of a stored credential whose access_token is used as the subject token, in place of subject_token.",
+			},
+			"subject_token_type": {
+				Type:        framework.TypeString,
+				Default:     provider.TokenTypeAccessToken,
+				Description: "RFC 8693 token type identifier of the subject token.",
+			},
+			"actor_token": {
+				Type:        framework.TypeString,
+				Description: "Actor token to exchange, if any.",
+			},
+			"actor_token_type": {
+				Type:        framework.TypeString,
+				Description: "RFC 8693 token type identifier of the actor token. Required if actor_token is set.",
+			},
+			"audience": {
+				Type:        framework.TypeString,
+				Description: "Requested audience of the resulting token.",
+			},
+			"resource": {
+				Type:        framework.TypeString,
+				Description: "Requested resource of the resulting token.",
+			},
+			"scopes": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Requested scopes of the resulting token.",
+			},
+			"requested_token_type": {
+				Type:        framework.TypeString,
+				Description: "RFC 8693 token type identifier requested of the resulting token.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathCredsExchangeWrite,
+		},
+		HelpSynopsis:    "Trade a subject token for a new token via RFC 8693 token exchange.",
+		HelpDescription: "Performs an RFC 8693 token exchange and stores the result as a new credential under self/:name, the same as self/:name read would return.",
+	}
+}
+
+func (b *backend) pathCredsExchangeWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	ops, err := b.privateOps(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	params := provider.TokenExchangeParams{
+		SubjectToken:       data.Get("subject_token").(string),
+		SubjectTokenType:   data.Get("subject_token_type").(string),
+		ActorToken:         data.Get("actor_token").(string),
+		ActorTokenType:     data.Get("actor_token_type").(string),
+		Audience:           data.Get("audience").(string),
+		Resource:           data.Get("resource").(string),
+		Scope:              data.Get("scopes").([]string),
+		RequestedTokenType: data.Get("requested_token_type").(string),
+	}
+
+	if params.SubjectToken == "" {
+		credName := data.Get("subject_token_credential").(string)
+		if credName == "" {
+			return logical.ErrorResponse("one of subject_token or subject_token_credential is required"), nil
+		}
+
+		subject, err := b.credential(ctx, req.Storage, credName)
+		if err != nil {
+			return nil, err
+		}
+		if subject == nil {
+			return logical.ErrorResponse("no such credential %q", credName), nil
+		}
+		params.SubjectToken = subject.Token.AccessToken
+	}
+
+	token, err := ops.TokenExchange(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &persist.CredentialEntry{Name: name, Token: token, IssuedAt: time.Now()}
+
+	if oidcOps, ok := ops.(provider.OIDCOperations); ok {
+		if claims, err := oidcOps.VerifyIDToken(ctx, token, "", nil); err == nil {
+			entry.LastVerifiedClaims = claims.Raw
+		}
+		// A token-exchange response isn't required to carry an id_token at
+		// all (RFC 8693 doesn't define one), so the absence of one here
+		// isn't an error the way it would be for an authorization code or
+		// refresh response.
+	}
+
+	if err := b.putCredential(ctx, req.Storage, entry); err != nil {
+		return nil, fmt.Errorf("backend: storing exchanged credential %q: %w", name, err)
+	}
+
+	return credResponse(entry), nil
+}
@@ -0,0 +1,30 @@
+// Package persist defines the storage entries the backend persists to
+// Vault's logical storage.
+package persist
+
+import "time"
+
+// AuthCodeEntry is the pending state for an in-flight authorization code
+// flow, stored under "auth-code/<state>" between the call that issues the
+// authorization URL and the one that redeems the resulting code.
+type AuthCodeEntry struct {
+	// Name is the credential this flow will populate once the code is
+	// redeemed.
+	Name string `json:"name"`
+
+	// RedirectURL is the redirect_uri that was sent with the authorization
+	// request and must be repeated on the token request.
+	RedirectURL string `json:"redirect_url"`
+
+	// PKCEVerifier is the RFC 7636 code_verifier generated when the
+	// authorization URL was issued, persisted so that the exchange step can
+	// send it on the token request without the caller needing to remember
+	// it. Empty when PKCE is not in use.
+	PKCEVerifier string `json:"pkce_verifier,omitempty"`
+
+	// Nonce is the OIDC nonce sent with the authorization request, if any,
+	// repeated to VerifyIDToken when the resulting id_token is verified.
+	Nonce string `json:"nonce,omitempty"`
+
+	IssuedAt time.Time `json:"issued_at"`
+}
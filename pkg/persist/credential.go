@@ -0,0 +1,30 @@
+package persist
+
+import (
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// CredentialEntry is the long-lived state persisted for a named credential
+// under "creds/<name>".
+type CredentialEntry struct {
+	Name string `json:"name"`
+
+	Token *oauth2.Token `json:"token,omitempty"`
+
+	// LastVerifiedClaims holds the raw claims from the most recent call to
+	// provider.OIDCOperations.VerifyIDToken for Token, so that they can be
+	// passed back in as that method's previous argument and carried forward
+	// across a refresh whose response didn't include a new id_token. Nil for
+	// a provider that isn't OIDC-aware, or before the first verification.
+	LastVerifiedClaims map[string]interface{} `json:"last_verified_claims,omitempty"`
+
+	IssuedAt  time.Time `json:"issued_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// DPoPKeyPKCS8 is the PKCS #8 DER encoding of the P-256 ECDSA private key
+	// Token is sender-constrained to, per RFC 9449. Nil if the credential
+	// wasn't bootstrapped with dpop=true.
+	DPoPKeyPKCS8 []byte `json:"dpop_key_pkcs8,omitempty"`
+}
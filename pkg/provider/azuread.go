@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/oauth2"
+)
+
+func init() {
+	GlobalRegistry.MustRegister("microsoft_azure_ad", AzureADFactory)
+}
+
+const azureADDefaultTenant = "organizations"
+
+// AzureADFactory builds a Provider for Azure Active Directory's v2.0
+// endpoint. Azure AD is multi-tenant: the tenant segment of the endpoint
+// URLs may be fixed by the operator at mount time via the `tenant` plugin
+// option, or, if left unset, supplied per authorization request.
+var AzureADFactory Factory = func(ctx context.Context, options map[string]string) (Provider, error) {
+	authFactory, err := newClientAuthenticatorFactory(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &baseProvider{
+		resolver:             &azureADEndpointResolver{tenant: options["tenant"]},
+		authenticatorFactory: authFactory,
+	}, nil
+}
+
+type azureADEndpointResolver struct {
+	// tenant is the operator-configured tenant. When set it always takes
+	// precedence over a tenant supplied at the request level, since
+	// operators configure a tenant specifically to pin the mount to it.
+	tenant string
+}
+
+func (r *azureADEndpointResolver) resolveEndpoint(providerOptions map[string]string) Endpoint {
+	tenant := r.tenant
+	if tenant == "" {
+		tenant = providerOptions["tenant"]
+	}
+	if tenant == "" {
+		tenant = azureADDefaultTenant
+	}
+
+	return Endpoint{
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/authorize", tenant),
+			TokenURL:  fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", tenant),
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		DeviceAuthURL:                 fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/devicecode", tenant),
+		PushedAuthorizationRequestURL: fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/par", tenant),
+	}
+}
@@ -0,0 +1,18 @@
+package provider
+
+import "context"
+
+// BasicFactory builds a Factory for a provider whose endpoint is fixed and
+// requires no plugin-level configuration beyond client authentication
+// (auth_style and friends). It is primarily useful for tests and for
+// providers that do not vary their endpoints by tenant or region.
+func BasicFactory(ep Endpoint) Factory {
+	return func(ctx context.Context, options map[string]string) (Provider, error) {
+		authFactory, err := newClientAuthenticatorFactory(options)
+		if err != nil {
+			return nil, err
+		}
+
+		return &baseProvider{resolver: staticEndpointResolver(ep), authenticatorFactory: authFactory}, nil
+	}
+}
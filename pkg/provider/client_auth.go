@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ClientAuthenticator authenticates an outgoing token request as a
+// particular OAuth 2.0 client. Private(...) invokes it immediately before
+// every grant (authorization code exchange, refresh, client credentials,
+// and token exchange), giving it the chance to set request body parameters
+// (client_secret_post, client_secret_jwt, private_key_jwt), request headers
+// (client_secret_basic), or nothing at all when authentication instead
+// happens at the transport layer (tls_client_auth).
+type ClientAuthenticator interface {
+	Authenticate(ctx context.Context, req *http.Request, form url.Values) error
+}
+
+// clientSecretPostAuthenticator implements the client_secret_post method
+// (RFC 6749 section 2.3.1): client_id and client_secret are sent as body
+// parameters. This is this package's long-standing default behavior.
+type clientSecretPostAuthenticator struct {
+	clientID, clientSecret string
+}
+
+// ClientSecretPostAuthenticator returns the client_secret_post
+// ClientAuthenticator.
+func ClientSecretPostAuthenticator(clientID, clientSecret string) ClientAuthenticator {
+	return clientSecretPostAuthenticator{clientID: clientID, clientSecret: clientSecret}
+}
+
+func (a clientSecretPostAuthenticator) Authenticate(_ context.Context, _ *http.Request, form url.Values) error {
+	form.Set("client_id", a.clientID)
+	if a.clientSecret != "" {
+		form.Set("client_secret", a.clientSecret)
+	}
+	return nil
+}
+
+// clientSecretBasicAuthenticator implements the client_secret_basic method:
+// client_id and client_secret are sent as HTTP Basic credentials.
+type clientSecretBasicAuthenticator struct {
+	clientID, clientSecret string
+}
+
+// ClientSecretBasicAuthenticator returns the client_secret_basic
+// ClientAuthenticator.
+func ClientSecretBasicAuthenticator(clientID, clientSecret string) ClientAuthenticator {
+	return clientSecretBasicAuthenticator{clientID: clientID, clientSecret: clientSecret}
+}
+
+func (a clientSecretBasicAuthenticator) Authenticate(_ context.Context, req *http.Request, _ url.Values) error {
+	req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+	return nil
+}
+
+// jwtBearerAuthenticator implements the client_secret_jwt and
+// private_key_jwt methods (RFC 7523 section 2.2): client_id is sent as a
+// body parameter alongside a signed JWT assertion whose audience is the
+// token endpoint itself.
+type jwtBearerAuthenticator struct {
+	clientID string
+	signer   JWTSigner
+	audience string
+}
+
+const clientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// ClientSecretJWTAuthenticator returns the client_secret_jwt
+// ClientAuthenticator, which signs the assertion with an HMAC over the
+// client secret. audience overrides the assertion's "aud" claim (which
+// otherwise defaults to the token endpoint URL) for providers that expect a
+// specific issuer identifier there instead.
+func ClientSecretJWTAuthenticator(clientID, clientSecret, audience string) ClientAuthenticator {
+	return &jwtBearerAuthenticator{clientID: clientID, signer: HMACSigner{Key: []byte(clientSecret)}, audience: audience}
+}
+
+// PrivateKeyJWTAuthenticator returns the private_key_jwt ClientAuthenticator,
+// which signs the assertion with signer. Use an RS256Signer, ES256Signer,
+// EdDSASigner, or a TransitSigner backed by a Vault Transit key. audience
+// overrides the assertion's "aud" claim as in ClientSecretJWTAuthenticator.
+func PrivateKeyJWTAuthenticator(clientID string, signer JWTSigner, audience string) ClientAuthenticator {
+	return &jwtBearerAuthenticator{clientID: clientID, signer: signer, audience: audience}
+}
+
+func (a *jwtBearerAuthenticator) Authenticate(_ context.Context, req *http.Request, form url.Values) error {
+	audience := a.audience
+	if audience == "" {
+		audience = req.URL.String()
+	}
+
+	now := time.Now()
+	assertion, err := signJWT(a.signer, map[string]interface{}{
+		"iss": a.clientID,
+		"sub": a.clientID,
+		"aud": audience,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+		"jti": fmt.Sprintf("%d", now.UnixNano()),
+	})
+	if err != nil {
+		return err
+	}
+
+	form.Set("client_id", a.clientID)
+	form.Set("client_assertion_type", clientAssertionTypeJWTBearer)
+	form.Set("client_assertion", assertion)
+
+	return nil
+}
+
+// tlsClientAuthAuthenticator implements tls_client_auth (RFC 8705): the
+// client is authenticated by the mutual-TLS connection itself, established
+// by an *http.Client the operator configured with a client certificate, so
+// no body parameter beyond client_id is needed.
+type tlsClientAuthAuthenticator struct {
+	clientID string
+}
+
+// TLSClientAuthAuthenticator returns the tls_client_auth ClientAuthenticator.
+// The *http.Client supplied via the request context's oauth2.HTTPClient
+// value (as with every other HTTP call this package makes) must already be
+// configured with the client certificate the provider expects; this
+// authenticator does not itself manage TLS configuration.
+func TLSClientAuthAuthenticator(clientID string) ClientAuthenticator {
+	return tlsClientAuthAuthenticator{clientID: clientID}
+}
+
+func (a tlsClientAuthAuthenticator) Authenticate(_ context.Context, _ *http.Request, form url.Values) error {
+	form.Set("client_id", a.clientID)
+	return nil
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// Supported auth_style plugin option values.
+const (
+	AuthStyleClientSecretPost  = "client_secret_post"
+	AuthStyleClientSecretBasic = "client_secret_basic"
+	AuthStyleClientSecretJWT   = "client_secret_jwt"
+	AuthStylePrivateKeyJWT     = "private_key_jwt"
+	AuthStyleTLSClientAuth     = "tls_client_auth"
+)
+
+// clientAuthenticatorFactory builds the ClientAuthenticator to use for a
+// given client ID/secret pair. Factories construct one from plugin options
+// once, at provider-creation time, so that a misconfigured auth_style or
+// signing key is reported when the mount is configured rather than on the
+// first credential request.
+type clientAuthenticatorFactory func(clientID, clientSecret string) ClientAuthenticator
+
+// newClientAuthenticatorFactory parses the auth_style, jwt_signing_key,
+// jwt_signing_algorithm, jwt_audience, and transit_key plugin options and
+// returns a clientAuthenticatorFactory for the requested method. An unset
+// auth_style preserves this package's historical client_secret_post
+// behavior.
+func newClientAuthenticatorFactory(options map[string]string) (clientAuthenticatorFactory, error) {
+	style := options["auth_style"]
+	if style == "" {
+		style = AuthStyleClientSecretPost
+	}
+
+	switch style {
+	case AuthStyleClientSecretPost:
+		return func(clientID, clientSecret string) ClientAuthenticator {
+			return ClientSecretPostAuthenticator(clientID, clientSecret)
+		}, nil
+
+	case AuthStyleClientSecretBasic:
+		return func(clientID, clientSecret string) ClientAuthenticator {
+			return ClientSecretBasicAuthenticator(clientID, clientSecret)
+		}, nil
+
+	case AuthStyleClientSecretJWT:
+		audience := options["jwt_audience"]
+		return func(clientID, clientSecret string) ClientAuthenticator {
+			return ClientSecretJWTAuthenticator(clientID, clientSecret, audience)
+		}, nil
+
+	case AuthStyleTLSClientAuth:
+		return func(clientID, _ string) ClientAuthenticator {
+			return TLSClientAuthAuthenticator(clientID)
+		}, nil
+
+	case AuthStylePrivateKeyJWT:
+		signer, err := privateKeyJWTSigner(options)
+		if err != nil {
+			return nil, err
+		}
+		audience := options["jwt_audience"]
+		return func(clientID, _ string) ClientAuthenticator {
+			return PrivateKeyJWTAuthenticator(clientID, signer, audience)
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("provider: unsupported auth_style %q", style)
+	}
+}
+
+func privateKeyJWTSigner(options map[string]string) (JWTSigner, error) {
+	algorithm := options["jwt_signing_algorithm"]
+
+	if transitKey := options["transit_key"]; transitKey != "" {
+		// Signing against a Transit key means a call to Vault's own Transit
+		// secrets engine, which requires the api.Client the backend (not
+		// this package) holds. The backend is responsible for supplying a
+		// TransitSigner wired to that client in place of this error before
+		// the provider is ever used to make a request.
+		return nil, fmt.Errorf("provider: auth_style %q with transit_key %q requires a Transit-backed signer from the backend", AuthStylePrivateKeyJWT, transitKey)
+	}
+
+	keyPEM := options["jwt_signing_key"]
+	if keyPEM == "" {
+		return nil, fmt.Errorf("provider: auth_style %q requires jwt_signing_key or transit_key", AuthStylePrivateKeyJWT)
+	}
+
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("provider: jwt_signing_key is not valid PEM")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("provider: parsing jwt_signing_key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != "" && algorithm != "RS256" {
+			return nil, fmt.Errorf("provider: jwt_signing_algorithm %q does not match RSA jwt_signing_key", algorithm)
+		}
+		return RS256Signer{Key: key}, nil
+	case *ecdsa.PrivateKey:
+		if algorithm != "" && algorithm != "ES256" {
+			return nil, fmt.Errorf("provider: jwt_signing_algorithm %q does not match ECDSA jwt_signing_key", algorithm)
+		}
+		return ES256Signer{Key: key}, nil
+	case ed25519.PrivateKey:
+		if algorithm != "" && algorithm != "EdDSA" {
+			return nil, fmt.Errorf("provider: jwt_signing_algorithm %q does not match Ed25519 jwt_signing_key", algorithm)
+		}
+		return EdDSASigner{Key: key}, nil
+	default:
+		return nil, fmt.Errorf("provider: unsupported jwt_signing_key type %T", key)
+	}
+}
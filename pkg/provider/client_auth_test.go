@@ -0,0 +1,88 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestClientSecretBasicAuth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r := provider.NewRegistry()
+	r.MustRegister("basic", basicTestFactory)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		require.True(t, ok)
+		assert.Equal(t, "foo", user)
+		assert.Equal(t, "bar", pass)
+
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		data, err := url.ParseQuery(string(b))
+		require.NoError(t, err)
+		assert.Empty(t, data.Get("client_secret"))
+
+		_, _ = w.Write([]byte(`access_token=abcd&token_type=bearer&expires_in=60`))
+	})
+	c := &http.Client{Transport: &testutil.MockRoundTripper{Handler: h}}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c)
+
+	basicTest, err := r.New(ctx, "basic", map[string]string{"auth_style": provider.AuthStyleClientSecretBasic})
+	require.NoError(t, err)
+
+	token, err := basicTest.Private("foo", "bar").ClientCredentials(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "abcd", token.AccessToken)
+}
+
+func TestClientSecretJWTAuth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r := provider.NewRegistry()
+	r.MustRegister("basic", basicTestFactory)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		data, err := url.ParseQuery(string(b))
+		require.NoError(t, err)
+
+		assert.Equal(t, "foo", data.Get("client_id"))
+		assert.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", data.Get("client_assertion_type"))
+
+		parts := strings.Split(data.Get("client_assertion"), ".")
+		require.Len(t, parts, 3)
+
+		header, err := base64.RawURLEncoding.DecodeString(parts[0])
+		require.NoError(t, err)
+		assert.Contains(t, string(header), `"alg":"HS256"`)
+
+		_, _ = w.Write([]byte(`access_token=abcd&token_type=bearer&expires_in=60`))
+	})
+	c := &http.Client{Transport: &testutil.MockRoundTripper{Handler: h}}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c)
+
+	basicTest, err := r.New(ctx, "basic", map[string]string{"auth_style": provider.AuthStyleClientSecretJWT})
+	require.NoError(t, err)
+
+	token, err := basicTest.Private("foo", "bar").ClientCredentials(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "abcd", token.AccessToken)
+}
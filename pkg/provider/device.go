@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrDeviceAuthorizationUnsupported is returned by DeviceAuthorization when
+// the provider has no device authorization endpoint configured.
+var ErrDeviceAuthorizationUnsupported = errors.New("provider: device authorization is not supported by this provider")
+
+// DeviceAuthorization is the response to a device authorization request, as
+// defined by RFC 8628 section 3.2.
+type DeviceAuthorization struct {
+	DeviceCode              string
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string
+	Interval                time.Duration
+	ExpiresIn               time.Duration
+}
+
+// DeviceAuthorization initiates the device authorization grant (RFC 8628),
+// returning the codes and verification URI the user should be directed to.
+// It returns ErrDeviceAuthorizationUnsupported if the provider has no device
+// authorization endpoint.
+func (o *operations) DeviceAuthorization(ctx context.Context, opts ...AuthCodeURLOption) (*DeviceAuthorization, error) {
+	ep := o.resolver.resolveEndpoint(nil)
+	if ep.DeviceAuthURL == "" {
+		return nil, ErrDeviceAuthorizationUnsupported
+	}
+
+	c := newAuthCodeURLConfig(opts)
+
+	v := url.Values{"client_id": {o.clientID}}
+	if len(c.scopes) > 0 {
+		v.Set("scope", strings.Join(c.scopes, " "))
+	}
+	for k, vals := range c.urlParams {
+		v.Set(k, vals[0])
+	}
+
+	values, status, _, err := postForm(ctx, o.auth(), ep.DeviceAuthURL, v, "")
+	if err != nil {
+		return nil, err
+	}
+	if status < 200 || status > 299 {
+		return nil, fmt.Errorf(
+			"provider: device authorization request to %s returned status %d: error=%q error_description=%q",
+			ep.DeviceAuthURL, status, values.Get("error"), values.Get("error_description"),
+		)
+	}
+
+	da := &DeviceAuthorization{
+		DeviceCode:              values.Get("device_code"),
+		UserCode:                values.Get("user_code"),
+		VerificationURI:         values.Get("verification_uri"),
+		VerificationURIComplete: values.Get("verification_uri_complete"),
+	}
+
+	if interval := values.Get("interval"); interval != "" {
+		if secs, err := strconv.ParseInt(interval, 10, 64); err == nil {
+			da.Interval = time.Duration(secs) * time.Second
+		}
+	}
+	if da.Interval == 0 {
+		da.Interval = 5 * time.Second
+	}
+
+	if expiresIn := values.Get("expires_in"); expiresIn != "" {
+		if secs, err := strconv.ParseInt(expiresIn, 10, 64); err == nil {
+			da.ExpiresIn = time.Duration(secs) * time.Second
+		}
+	}
+
+	return da, nil
+}
+
+const grantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+// Device authorization error codes defined by RFC 8628 section 3.5 that
+// indicate the caller should keep polling.
+const (
+	deviceErrorAuthorizationPending = "authorization_pending"
+	deviceErrorSlowDown             = "slow_down"
+)
+
+// DevicePollToken polls the token endpoint for the result of a device
+// authorization identified by deviceCode, honoring the spec's polling
+// protocol: it blocks, sleeping interval between attempts (and backing off
+// per "slow_down" responses), until the provider issues a token or returns
+// a terminal error such as "access_denied" or "expired_token", or ctx is
+// canceled.
+func (o *operations) DevicePollToken(ctx context.Context, deviceCode string, interval time.Duration) (*oauth2.Token, error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		v := url.Values{
+			"grant_type":  {grantTypeDeviceCode},
+			"device_code": {deviceCode},
+		}
+
+		token, err := retrieveToken(ctx, o.auth(), o.resolver.resolveEndpoint(nil).TokenURL, v, nil)
+		if err == nil {
+			return token, nil
+		}
+
+		var reqErr *tokenRequestError
+		if !errors.As(err, &reqErr) {
+			return nil, err
+		}
+
+		switch reqErr.Code {
+		case deviceErrorAuthorizationPending:
+			continue
+		case deviceErrorSlowDown:
+			interval += 5 * time.Second
+			continue
+		default:
+			return nil, reqErr
+		}
+	}
+}
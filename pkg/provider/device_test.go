@@ -0,0 +1,75 @@
+package provider_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestDeviceAuthorizationGrant(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r := provider.NewRegistry()
+	r.MustRegister("basic", provider.BasicFactory(provider.Endpoint{
+		Endpoint: oauth2.Endpoint{
+			TokenURL: "http://localhost/token",
+		},
+		DeviceAuthURL: "http://localhost/device",
+	}))
+
+	attempt := 0
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		data, err := url.ParseQuery(string(b))
+		require.NoError(t, err)
+
+		switch r.URL.Path {
+		case "/device":
+			assert.Equal(t, "foo", data.Get("client_id"))
+			_, _ = w.Write([]byte(`{"device_code":"devcode","user_code":"USER-CODE","verification_uri":"http://localhost/verify","interval":0,"expires_in":600}`))
+		case "/token":
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", data.Get("grant_type"))
+			assert.Equal(t, "devcode", data.Get("device_code"))
+
+			attempt++
+			if attempt < 2 {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+
+			_, _ = w.Write([]byte(`access_token=abcd&token_type=bearer&expires_in=60`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	c := &http.Client{Transport: &testutil.MockRoundTripper{Handler: h}}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c)
+
+	basicTest, err := r.New(ctx, "basic", map[string]string{})
+	require.NoError(t, err)
+
+	ops := basicTest.Private("foo", "bar")
+
+	da, err := ops.DeviceAuthorization(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "devcode", da.DeviceCode)
+	require.Equal(t, "USER-CODE", da.UserCode)
+
+	token, err := ops.DevicePollToken(ctx, da.DeviceCode, time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, "abcd", token.AccessToken)
+	require.Equal(t, 2, attempt)
+}
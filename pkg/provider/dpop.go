@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// dpopProofHeader is the HTTP header a DPoP proof JWT travels in, per RFC
+// 9449 section 4.
+const dpopProofHeader = "DPoP"
+
+// dpopNonceHeader is the HTTP header a provider returns a fresh nonce in,
+// either on a 401 challenge or alongside a successful token response, per
+// RFC 9449 section 8.
+const dpopNonceHeader = "DPoP-Nonce"
+
+// WithDPoPKey binds the access token a grant returns to signer's private
+// key, per RFC 9449: the token request carries a proof JWT (header
+// typ:"dpop+jwt", alg, jwk; payload htm, htu, iat, jti) signed by signer, so
+// that presenting the resulting access token without also possessing signer
+// is not enough to use it. If the provider challenges the first attempt
+// with a 401 and a DPoP-Nonce header, the request is retried once with that
+// nonce echoed back in the proof, as the spec requires.
+//
+// signer must be an *ecdsa.PrivateKey on the P-256 curve, an
+// *rsa.PrivateKey, or an ed25519.PrivateKey; the resulting proof is signed
+// ES256, RS256, or EdDSA respectively. Callers that persist credentials
+// across Vault restarts are responsible for persisting signer's key
+// material alongside the credential themselves and supplying the same key
+// on every subsequent AuthCodeExchange, RefreshToken, or ClientCredentials
+// call, since a new key would no longer match the access token's binding.
+func WithDPoPKey(signer crypto.Signer) TokenRequestOption { return dpopKeyOption{signer: signer} }
+
+type dpopKeyOption struct {
+	signer crypto.Signer
+}
+
+func (o dpopKeyOption) applyTokenRequest(c *tokenRequestConfig) { c.dpopSigner = o.signer }
+
+// dpopProofSigner adapts signer to the JWTSigner this package's existing
+// signing helpers expect, and derives the RFC 7517 JSON Web Key its public
+// half corresponds to, for use in a DPoP proof's "jwk" header.
+func dpopProofSigner(signer crypto.Signer) (JWTSigner, map[string]interface{}, error) {
+	switch key := signer.(type) {
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, nil, fmt.Errorf("provider: DPoP keys must use the P-256 curve, got %s", key.Curve.Params().Name)
+		}
+
+		const coordinateSize = 32
+		x := make([]byte, coordinateSize)
+		y := make([]byte, coordinateSize)
+		key.X.FillBytes(x)
+		key.Y.FillBytes(y)
+
+		jwk := map[string]interface{}{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(x),
+			"y":   base64.RawURLEncoding.EncodeToString(y),
+		}
+		return ES256Signer{Key: key}, jwk, nil
+	case *rsa.PrivateKey:
+		jwk := map[string]interface{}{
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+		return RS256Signer{Key: key}, jwk, nil
+	case ed25519.PrivateKey:
+		pub, _ := key.Public().(ed25519.PublicKey)
+		jwk := map[string]interface{}{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   base64.RawURLEncoding.EncodeToString(pub),
+		}
+		return EdDSASigner{Key: key}, jwk, nil
+	default:
+		return nil, nil, fmt.Errorf("provider: unsupported DPoP key type %T", signer)
+	}
+}
+
+// dpopProofJWT builds and signs the proof JWT described by RFC 9449 section
+// 4.2 for a request with method htm to URL htu, binding it to the server's
+// nonce when one has been challenged for.
+func dpopProofJWT(signer JWTSigner, jwk map[string]interface{}, htm, htu, nonce string) (string, error) {
+	header, err := json.Marshal(map[string]interface{}{
+		"typ": "dpop+jwt",
+		"alg": signer.Alg(),
+		"jwk": jwk,
+	})
+	if err != nil {
+		return "", fmt.Errorf("provider: marshaling DPoP proof header: %w", err)
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("provider: generating DPoP proof jti: %w", err)
+	}
+
+	claims := map[string]interface{}{
+		"jti": base64.RawURLEncoding.EncodeToString(jti),
+		"htm": htm,
+		"htu": htu,
+		"iat": time.Now().Unix(),
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("provider: marshaling DPoP proof claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	sig, err := signer.Sign([]byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("provider: signing DPoP proof: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// GenerateDPoPProof builds a standalone RFC 9449 DPoP proof JWT for an HTTP
+// request with method htm to URL htu, signed by signer, without involving a
+// token request. It is the primitive a `self/:name/dpop-proof` style
+// read endpoint would call to hand a caller a fresh proof for a downstream
+// API call without exporting signer's private key; this package implements
+// only the provider client, not the Vault backend paths that would serve
+// such an endpoint, so exposing it over HTTP is left to that layer.
+func GenerateDPoPProof(signer crypto.Signer, htm, htu string) (string, error) {
+	jwtSigner, jwk, err := dpopProofSigner(signer)
+	if err != nil {
+		return "", err
+	}
+	return dpopProofJWT(jwtSigner, jwk, htm, htu, "")
+}
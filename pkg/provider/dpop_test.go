@@ -0,0 +1,110 @@
+package provider_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestDPoPBoundTokenRequest(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	attempt := 0
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		data, err := url.ParseQuery(string(b))
+		require.NoError(t, err)
+		assert.Equal(t, "client_credentials", data.Get("grant_type"))
+
+		claims := decodeDPoPProof(t, r.Header.Get("DPoP"))
+		assert.Equal(t, "POST", claims["htm"])
+		assert.Equal(t, "http://localhost/token", claims["htu"])
+
+		if attempt == 1 {
+			assert.Empty(t, claims["nonce"])
+
+			w.Header().Set("DPoP-Nonce", "server-nonce")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"use_dpop_nonce"}`))
+			return
+		}
+
+		assert.Equal(t, "server-nonce", claims["nonce"])
+		_, _ = w.Write([]byte(`access_token=abcd&token_type=DPoP&expires_in=60`))
+	})
+	c := &http.Client{Transport: &testutil.MockRoundTripper{Handler: h}}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c)
+
+	r := provider.NewRegistry()
+	r.MustRegister("basic", provider.BasicFactory(provider.Endpoint{
+		Endpoint: oauth2.Endpoint{TokenURL: "http://localhost/token"},
+	}))
+
+	basicTest, err := r.New(ctx, "basic", map[string]string{})
+	require.NoError(t, err)
+
+	token, err := basicTest.Private("foo", "bar").ClientCredentials(ctx, provider.WithDPoPKey(key))
+	require.NoError(t, err)
+	require.Equal(t, "abcd", token.AccessToken)
+	require.Equal(t, 2, attempt)
+}
+
+func TestGenerateDPoPProof(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	proof, err := provider.GenerateDPoPProof(key, "GET", "https://api.example.com/resource")
+	require.NoError(t, err)
+
+	claims := decodeDPoPProof(t, proof)
+	assert.Equal(t, "GET", claims["htm"])
+	assert.Equal(t, "https://api.example.com/resource", claims["htu"])
+	assert.NotEmpty(t, claims["jti"])
+}
+
+func decodeDPoPProof(t *testing.T, proof string) map[string]interface{} {
+	t.Helper()
+
+	parts := strings.Split(proof, ".")
+	require.Len(t, parts, 3)
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+
+	var headerClaims map[string]interface{}
+	require.NoError(t, json.Unmarshal(header, &headerClaims))
+	assert.Equal(t, "dpop+jwt", headerClaims["typ"])
+	assert.Equal(t, "ES256", headerClaims["alg"])
+	assert.NotNil(t, headerClaims["jwk"])
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+
+	var claims map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &claims))
+
+	return claims
+}
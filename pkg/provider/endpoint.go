@@ -0,0 +1,21 @@
+package provider
+
+import "golang.org/x/oauth2"
+
+// Endpoint describes the OAuth 2.0 endpoints of a provider. It wraps
+// golang.org/x/oauth2's Endpoint so that providers can be constructed from
+// the same values accepted upstream while leaving room for endpoints that
+// the upstream package does not model.
+type Endpoint struct {
+	oauth2.Endpoint
+
+	// DeviceAuthURL is the device authorization endpoint defined by RFC
+	// 8628. It is empty for providers that do not support the device
+	// authorization grant.
+	DeviceAuthURL string
+
+	// PushedAuthorizationRequestURL is the pushed authorization request
+	// endpoint defined by RFC 9126. It is empty for providers that do not
+	// support PAR.
+	PushedAuthorizationRequestURL string
+}
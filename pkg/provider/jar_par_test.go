@@ -0,0 +1,137 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestJARAuthCodeURL(t *testing.T) {
+	ctx := context.Background()
+
+	r := provider.NewRegistry()
+	r.MustRegister("basic", basicTestFactory)
+
+	basicTest, err := r.New(ctx, "basic", map[string]string{})
+	require.NoError(t, err)
+
+	ops := basicTest.Public("foo")
+
+	authCodeURL, ok := ops.AuthCodeURL(
+		"state",
+		provider.WithScopes{"a", "b"},
+		provider.WithJARSigner(provider.HMACSigner{Key: []byte("secret")}),
+	)
+	require.True(t, ok)
+
+	u, err := url.Parse(authCodeURL)
+	require.NoError(t, err)
+
+	qs := u.Query()
+	assert.Equal(t, "foo", qs.Get("client_id"))
+	assert.Empty(t, qs.Get("state"))
+	assert.Empty(t, qs.Get("scope"))
+
+	claims := decodeJARRequest(t, qs.Get("request"))
+	assert.Equal(t, "code", claims["response_type"])
+	assert.Equal(t, "foo", claims["client_id"])
+	assert.Equal(t, "state", claims["state"])
+	assert.Equal(t, "a b", claims["scope"])
+}
+
+func TestPushedAuthorizationRequestURL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r := provider.NewRegistry()
+	r.MustRegister("basic", provider.BasicFactory(provider.Endpoint{
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "http://localhost/authorize",
+			TokenURL: "http://localhost/token",
+		},
+		PushedAuthorizationRequestURL: "http://localhost/par",
+	}))
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/par":
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			data, err := url.ParseQuery(string(b))
+			require.NoError(t, err)
+			assert.Equal(t, "foo", data.Get("client_id"))
+			assert.Equal(t, "state", data.Get("state"))
+
+			_, _ = w.Write([]byte(`{"request_uri":"urn:ietf:params:oauth:request_uri:abcd","expires_in":60}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	c := &http.Client{Transport: &testutil.MockRoundTripper{Handler: h}}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c)
+
+	basicTest, err := r.New(ctx, "basic", map[string]string{})
+	require.NoError(t, err)
+
+	ops := basicTest.Public("foo")
+
+	parOps, ok := ops.(provider.PARCapableOperations)
+	require.True(t, ok)
+
+	authCodeURL, err := parOps.PushedAuthorizationRequestURL(ctx, "state")
+	require.NoError(t, err)
+
+	u, err := url.Parse(authCodeURL)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/authorize", u.Path)
+
+	qs := u.Query()
+	assert.Equal(t, "foo", qs.Get("client_id"))
+	assert.Equal(t, "urn:ietf:params:oauth:request_uri:abcd", qs.Get("request_uri"))
+	assert.Empty(t, qs.Get("state"))
+}
+
+func TestPushedAuthorizationRequestURLUnsupported(t *testing.T) {
+	ctx := context.Background()
+
+	r := provider.NewRegistry()
+	r.MustRegister("basic", basicTestFactory)
+
+	basicTest, err := r.New(ctx, "basic", map[string]string{})
+	require.NoError(t, err)
+
+	parOps, ok := basicTest.Public("foo").(provider.PARCapableOperations)
+	require.True(t, ok)
+
+	_, err = parOps.PushedAuthorizationRequestURL(ctx, "state")
+	assert.ErrorIs(t, err, provider.ErrPARUnsupported)
+}
+
+func decodeJARRequest(t *testing.T, requestJWT string) map[string]interface{} {
+	t.Helper()
+
+	parts := strings.Split(requestJWT, ".")
+	require.Len(t, parts, 3)
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+
+	var claims map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &claims))
+
+	return claims
+}
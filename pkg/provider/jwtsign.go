@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// JWTSigner produces the signature half of a compact JWS, as used by the
+// client_secret_jwt and private_key_jwt client authentication methods (RFC
+// 7523) and by JAR request object signing.
+type JWTSigner interface {
+	// Alg is the JWS "alg" header value this signer produces, e.g. "HS256",
+	// "RS256", "ES256", or "EdDSA".
+	Alg() string
+
+	// Sign returns the signature over signingInput, which is the ASCII
+	// bytes "<base64url header>.<base64url payload>".
+	Sign(signingInput []byte) ([]byte, error)
+}
+
+// signJWT builds a compact, signed JWT for claims using signer.
+func signJWT(signer JWTSigner, claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": signer.Alg(), "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("provider: marshaling JWT header: %w", err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("provider: marshaling JWT claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	sig, err := signer.Sign([]byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("provider: signing JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// HMACSigner signs with HS256 using a shared secret. It backs the
+// client_secret_jwt authentication method, where the shared secret is the
+// client secret itself.
+type HMACSigner struct {
+	Key []byte
+}
+
+func (s HMACSigner) Alg() string { return "HS256" }
+
+func (s HMACSigner) Sign(signingInput []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write(signingInput)
+	return mac.Sum(nil), nil
+}
+
+// RS256Signer signs with RS256 using an RSA private key.
+type RS256Signer struct {
+	Key *rsa.PrivateKey
+}
+
+func (s RS256Signer) Alg() string { return "RS256" }
+
+func (s RS256Signer) Sign(signingInput []byte) ([]byte, error) {
+	sum := sha256.Sum256(signingInput)
+	return rsa.SignPKCS1v15(rand.Reader, s.Key, crypto.SHA256, sum[:])
+}
+
+// ES256Signer signs with ES256 using an ECDSA P-256 private key. Unlike
+// crypto/ecdsa's default ASN.1 DER encoding, JWS requires the fixed-width
+// R || S encoding produced here.
+type ES256Signer struct {
+	Key *ecdsa.PrivateKey
+}
+
+func (s ES256Signer) Alg() string { return "ES256" }
+
+func (s ES256Signer) Sign(signingInput []byte) ([]byte, error) {
+	sum := sha256.Sum256(signingInput)
+
+	r, sVal, err := ecdsa.Sign(rand.Reader, s.Key, sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	const size = 32 // P-256 coordinate width in bytes.
+	sig := make([]byte, 2*size)
+	r.FillBytes(sig[:size])
+	sVal.FillBytes(sig[size:])
+
+	return sig, nil
+}
+
+// EdDSASigner signs with EdDSA using an Ed25519 private key.
+type EdDSASigner struct {
+	Key ed25519.PrivateKey
+}
+
+func (s EdDSASigner) Alg() string { return "EdDSA" }
+
+func (s EdDSASigner) Sign(signingInput []byte) ([]byte, error) {
+	return ed25519.Sign(s.Key, signingInput), nil
+}
+
+// TransitSignFunc signs a digest using a key held by Vault's Transit secrets
+// engine, keeping the private key material out of this plugin's process.
+type TransitSignFunc func(signingInput []byte) ([]byte, error)
+
+// TransitSigner adapts a Vault Transit signing call to JWTSigner, for
+// operators who configure `transit_key` instead of loading PEM bytes into
+// the plugin.
+type TransitSigner struct {
+	Algorithm string
+	SignFunc  TransitSignFunc
+}
+
+func (s TransitSigner) Alg() string { return s.Algorithm }
+
+func (s TransitSigner) Sign(signingInput []byte) ([]byte, error) {
+	return s.SignFunc(signingInput)
+}
@@ -0,0 +1,222 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// OIDCClaims are the verified claims extracted from an ID token.
+type OIDCClaims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	Expiry    time.Time
+	NotBefore time.Time
+	Nonce     string
+
+	// Raw holds the complete set of claims as decoded from the token
+	// payload, for consumers that need a claim this type does not surface
+	// directly.
+	Raw map[string]interface{}
+}
+
+// OIDCOperations is implemented by the operations of a provider that
+// advertises OpenID Connect discovery. Callers obtain it with a type
+// assertion on the PublicOperations or PrivateOperations returned by a
+// Provider, and invoke it explicitly after AuthCodeExchange or RefreshToken
+// returns a token:
+//
+//	if oidcOps, ok := ops.(provider.OIDCOperations); ok {
+//	    claims, err := oidcOps.VerifyIDToken(ctx, token, nonce, previous)
+//	}
+//
+// This package does not itself call VerifyIDToken or cache its result: it is
+// opt-in and re-run on every token the caller wants claims from. previous
+// follows the same convention RefreshToken uses for a non-rotating refresh
+// token: a refresh that returns a new id_token is re-verified against it,
+// and a refresh that doesn't (token carries no id_token at all) returns
+// previous unchanged instead of an error. A caller that wants claims
+// preserved across such a refresh passes the last value VerifyIDToken gave
+// it; one that doesn't care, or is verifying an initial token, passes nil.
+type OIDCOperations interface {
+	// VerifyIDToken extracts the id_token from token's extra parameters and
+	// verifies its signature against the provider's published JWKS, along
+	// with its issuer, audience, expiry, and not-before claims. If nonce is
+	// non-empty it must match the token's nonce claim. If token carries no
+	// id_token, it returns previous unchanged when previous is non-nil, and
+	// an error otherwise.
+	VerifyIDToken(ctx context.Context, token *oauth2.Token, nonce string, previous *OIDCClaims) (*OIDCClaims, error)
+}
+
+// WithNonce sets the nonce parameter of an authorization request, binding
+// the resulting ID token to this request per the OIDC core spec.
+type WithNonce string
+
+func (o WithNonce) applyAuthCodeURL(c *authCodeURLConfig) {
+	if c.urlParams == nil {
+		c.urlParams = make(url.Values, 1)
+	}
+	c.urlParams.Set("nonce", string(o))
+}
+
+type oidcVerifier struct {
+	issuer    string
+	keySet    *oidcKeySet
+	clockSkew time.Duration
+}
+
+func newOIDCVerifier(md *OIDCMetadata) *oidcVerifier {
+	return &oidcVerifier{
+		issuer:    md.Issuer,
+		keySet:    newOIDCKeySet(md.JWKSURI),
+		clockSkew: time.Minute,
+	}
+}
+
+func (v *oidcVerifier) verify(ctx context.Context, rawIDToken, audience, nonce string) (*OIDCClaims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("provider: id_token is not a well-formed JWT")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("provider: decoding id_token header: %w", err)
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("provider: parsing id_token header: %w", err)
+	}
+
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("provider: unsupported id_token signing algorithm %q", header.Alg)
+	}
+
+	key, err := v.keySet.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("provider: decoding id_token signature: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], signature); err != nil {
+		return nil, fmt.Errorf("provider: id_token signature verification failed: %w", err)
+	}
+
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("provider: decoding id_token payload: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(payloadJSON, &raw); err != nil {
+		return nil, fmt.Errorf("provider: parsing id_token payload: %w", err)
+	}
+
+	claims, err := claimsFromRaw(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Issuer != v.issuer {
+		return nil, fmt.Errorf("provider: id_token issuer %q does not match expected issuer %q", claims.Issuer, v.issuer)
+	}
+
+	if !containsString(claims.Audience, audience) {
+		return nil, fmt.Errorf("provider: id_token audience %v does not contain client ID %q", claims.Audience, audience)
+	}
+
+	now := time.Now()
+	if !claims.Expiry.IsZero() && now.After(claims.Expiry.Add(v.clockSkew)) {
+		return nil, fmt.Errorf("provider: id_token is expired")
+	}
+	if !claims.NotBefore.IsZero() && now.Before(claims.NotBefore.Add(-v.clockSkew)) {
+		return nil, fmt.Errorf("provider: id_token is not yet valid")
+	}
+
+	if nonce != "" && claims.Nonce != nonce {
+		return nil, fmt.Errorf("provider: id_token nonce does not match the value sent with the authorization request")
+	}
+
+	return claims, nil
+}
+
+func claimsFromRaw(raw map[string]interface{}) (*OIDCClaims, error) {
+	c := &OIDCClaims{Raw: raw}
+
+	if iss, ok := raw["iss"].(string); ok {
+		c.Issuer = iss
+	}
+	if sub, ok := raw["sub"].(string); ok {
+		c.Subject = sub
+	}
+	if nonce, ok := raw["nonce"].(string); ok {
+		c.Nonce = nonce
+	}
+
+	switch aud := raw["aud"].(type) {
+	case string:
+		c.Audience = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				c.Audience = append(c.Audience, s)
+			}
+		}
+	}
+
+	if exp, ok := raw["exp"].(float64); ok {
+		c.Expiry = time.Unix(int64(exp), 0)
+	}
+	if nbf, ok := raw["nbf"].(float64); ok {
+		c.NotBefore = time.Unix(int64(nbf), 0)
+	}
+
+	return c, nil
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// oidcOperations decorates *operations with ID token verification for
+// providers whose factory completed OIDC discovery.
+type oidcOperations struct {
+	*operations
+
+	verifier *oidcVerifier
+}
+
+func (o *oidcOperations) VerifyIDToken(ctx context.Context, token *oauth2.Token, nonce string, previous *OIDCClaims) (*OIDCClaims, error) {
+	raw, ok := token.Extra("id_token").(string)
+	if !ok || raw == "" {
+		if previous != nil {
+			return previous, nil
+		}
+		return nil, fmt.Errorf("provider: token response did not include an id_token")
+	}
+
+	return o.verifier.verify(ctx, raw, o.clientID, nonce)
+}
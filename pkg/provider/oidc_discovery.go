@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// OIDCMetadata is the subset of an OpenID Connect discovery document
+// (RFC https://openid.net/specs/openid-connect-discovery-1_0.html) that this
+// module uses to populate a provider's endpoints and locate its key set.
+type OIDCMetadata struct {
+	Issuer                             string `json:"issuer"`
+	AuthorizationEndpoint              string `json:"authorization_endpoint"`
+	TokenEndpoint                      string `json:"token_endpoint"`
+	JWKSURI                            string `json:"jwks_uri"`
+	DeviceAuthorizationEndpoint        string `json:"device_authorization_endpoint"`
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint"`
+}
+
+// DiscoverOIDCMetadata fetches and parses the discovery document at
+// <issuerURL>/.well-known/openid-configuration using the HTTP client
+// associated with ctx (see golang.org/x/oauth2.HTTPClient), falling back to
+// http.DefaultClient.
+func DiscoverOIDCMetadata(ctx context.Context, issuerURL string) (*OIDCMetadata, error) {
+	wellKnown := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, fmt.Errorf("provider: building OIDC discovery request: %w", err)
+	}
+
+	resp, err := oidcHTTPClient(ctx).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("provider: fetching OIDC discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider: OIDC discovery document request to %s returned status %d", wellKnown, resp.StatusCode)
+	}
+
+	var md OIDCMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&md); err != nil {
+		return nil, fmt.Errorf("provider: decoding OIDC discovery document: %w", err)
+	}
+
+	if md.Issuer != issuerURL {
+		return nil, fmt.Errorf("provider: OIDC discovery document issuer %q does not match configured issuer %q", md.Issuer, issuerURL)
+	}
+
+	return &md, nil
+}
+
+func oidcHTTPClient(ctx context.Context) *http.Client {
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+)
+
+// OIDCAwareFactory wraps a Factory so that, when the operator supplies an
+// `issuer_url` plugin option, the resulting provider's endpoints are
+// populated from that issuer's `/.well-known/openid-configuration` document
+// and its operations gain the OIDCOperations surface for ID token
+// verification. When `issuer_url` is unset, the wrapped factory's
+// statically configured endpoints are used unchanged and no OIDC operations
+// are exposed.
+func OIDCAwareFactory(fallback Factory) Factory {
+	return func(ctx context.Context, options map[string]string) (Provider, error) {
+		issuerURL := options["issuer_url"]
+		if issuerURL == "" {
+			return fallback(ctx, options)
+		}
+
+		md, err := DiscoverOIDCMetadata(ctx, issuerURL)
+		if err != nil {
+			return nil, err
+		}
+
+		authFactory, err := newClientAuthenticatorFactory(options)
+		if err != nil {
+			return nil, err
+		}
+
+		resolver := staticEndpointResolver(Endpoint{
+			Endpoint: oauth2.Endpoint{
+				AuthURL:   md.AuthorizationEndpoint,
+				TokenURL:  md.TokenEndpoint,
+				AuthStyle: oauth2.AuthStyleInParams,
+			},
+			DeviceAuthURL:                 md.DeviceAuthorizationEndpoint,
+			PushedAuthorizationRequestURL: md.PushedAuthorizationRequestEndpoint,
+		})
+
+		return &oidcProvider{resolver: resolver, verifier: newOIDCVerifier(md), authenticatorFactory: authFactory}, nil
+	}
+}
+
+// oidcProvider is a Provider whose endpoints were populated by OIDC
+// discovery and whose operations additionally implement OIDCOperations.
+type oidcProvider struct {
+	resolver             endpointResolver
+	verifier             *oidcVerifier
+	authenticatorFactory clientAuthenticatorFactory
+}
+
+func (p *oidcProvider) Public(clientID string) PublicOperations {
+	return &oidcOperations{
+		operations: &operations{resolver: p.resolver, clientID: clientID},
+		verifier:   p.verifier,
+	}
+}
+
+func (p *oidcProvider) Private(clientID, clientSecret string) PrivateOperations {
+	return &oidcOperations{
+		operations: &operations{
+			resolver:      p.resolver,
+			clientID:      clientID,
+			clientSecret:  clientSecret,
+			authenticator: p.authenticatorFactory(clientID, clientSecret),
+		},
+		verifier: p.verifier,
+	}
+}
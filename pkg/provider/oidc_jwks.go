@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+)
+
+// oidcKeySet caches the RSA public keys published at a provider's jwks_uri,
+// keyed by `kid`. Keys are re-fetched on demand when an unrecognized `kid` is
+// encountered, which allows the provider to rotate signing keys without the
+// plugin needing to poll.
+type oidcKeySet struct {
+	jwksURI string
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+func newOIDCKeySet(jwksURI string) *oidcKeySet {
+	return &oidcKeySet{jwksURI: jwksURI, keys: make(map[string]*rsa.PublicKey)}
+}
+
+func (ks *oidcKeySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	ks.mu.Lock()
+	key, found := ks.keys[kid]
+	ks.mu.Unlock()
+
+	if found {
+		return key, nil
+	}
+
+	if err := ks.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	key, found = ks.keys[kid]
+	if !found {
+		return nil, fmt.Errorf("provider: no key found in JWKS for kid %q", kid)
+	}
+
+	return key, nil
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (ks *oidcKeySet) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.jwksURI, nil)
+	if err != nil {
+		return fmt.Errorf("provider: building JWKS request: %w", err)
+	}
+
+	resp, err := oidcHTTPClient(ctx).Do(req)
+	if err != nil {
+		return fmt.Errorf("provider: fetching JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("provider: JWKS request to %s returned status %d", ks.jwksURI, resp.StatusCode)
+	}
+
+	var set jsonWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("provider: decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			return fmt.Errorf("provider: parsing JWKS key %q: %w", jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+
+	return nil
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("decoding modulus: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("decoding exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
@@ -0,0 +1,211 @@
+package provider_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestOIDCVerifyIDToken(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	const kid = "test-key"
+	const issuer = "http://localhost/issuer"
+
+	sign := func(claims map[string]interface{}) string {
+		header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+		require.NoError(t, err)
+		payload, err := json.Marshal(claims)
+		require.NoError(t, err)
+
+		signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		sum := sha256.Sum256([]byte(signingInput))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+		require.NoError(t, err)
+
+		return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+	}
+
+	idToken := sign(map[string]interface{}{
+		"iss":   issuer,
+		"sub":   "user-1",
+		"aud":   "foo",
+		"nonce": "xyz",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nbf":   time.Now().Add(-time.Minute).Unix(),
+	})
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/issuer/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 issuer,
+				"authorization_endpoint": issuer + "/authorize",
+				"token_endpoint":         issuer + "/token",
+				"jwks_uri":               issuer + "/jwks",
+			})
+		case "/issuer/jwks":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"keys": []map[string]string{
+					{
+						"kty": "RSA",
+						"kid": kid,
+						"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+						"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+					},
+				},
+			})
+		case "/issuer/token":
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			data, err := url.ParseQuery(string(b))
+			require.NoError(t, err)
+			require.Equal(t, "123456", data.Get("code"))
+
+			fmt.Fprintf(w, `{"access_token":"abcd","token_type":"bearer","expires_in":60,"id_token":%q}`, idToken)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	c := &http.Client{Transport: &testutil.MockRoundTripper{Handler: h}}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c)
+
+	r := provider.NewRegistry()
+	r.MustRegister("oidc", provider.OIDCAwareFactory(provider.BasicFactory(provider.Endpoint{
+		Endpoint: oauth2.Endpoint{AuthURL: issuer + "/authorize", TokenURL: issuer + "/token"},
+	})))
+
+	p, err := r.New(ctx, "oidc", map[string]string{"issuer_url": issuer})
+	require.NoError(t, err)
+
+	ops := p.Private("foo", "bar")
+
+	token, err := ops.AuthCodeExchange(ctx, "123456")
+	require.NoError(t, err)
+
+	oidcOps, ok := ops.(provider.OIDCOperations)
+	require.True(t, ok)
+
+	claims, err := oidcOps.VerifyIDToken(ctx, token, "xyz", nil)
+	require.NoError(t, err)
+	require.Equal(t, issuer, claims.Issuer)
+	require.Equal(t, "user-1", claims.Subject)
+}
+
+func TestOIDCVerifyIDTokenPreservesClaimsAcrossNonRotatingRefresh(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	const kid = "test-key"
+	const issuer = "http://localhost/issuer"
+
+	sign := func(claims map[string]interface{}) string {
+		header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+		require.NoError(t, err)
+		payload, err := json.Marshal(claims)
+		require.NoError(t, err)
+
+		signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		sum := sha256.Sum256([]byte(signingInput))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+		require.NoError(t, err)
+
+		return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+	}
+
+	idToken := sign(map[string]interface{}{
+		"iss": issuer,
+		"sub": "user-1",
+		"aud": "foo",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/issuer/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 issuer,
+				"authorization_endpoint": issuer + "/authorize",
+				"token_endpoint":         issuer + "/token",
+				"jwks_uri":               issuer + "/jwks",
+			})
+		case "/issuer/jwks":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"keys": []map[string]string{
+					{
+						"kty": "RSA",
+						"kid": kid,
+						"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+						"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+					},
+				},
+			})
+		case "/issuer/token":
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			data, err := url.ParseQuery(string(b))
+			require.NoError(t, err)
+			require.Equal(t, "refresh_token", data.Get("grant_type"))
+
+			// A provider that doesn't rotate the ID token on refresh, per
+			// the refresh_token grant's optional id_token (OIDC core section 12.1).
+			fmt.Fprint(w, `{"access_token":"efgh","token_type":"bearer","expires_in":60}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	c := &http.Client{Transport: &testutil.MockRoundTripper{Handler: h}}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c)
+
+	r := provider.NewRegistry()
+	r.MustRegister("oidc", provider.OIDCAwareFactory(provider.BasicFactory(provider.Endpoint{
+		Endpoint: oauth2.Endpoint{AuthURL: issuer + "/authorize", TokenURL: issuer + "/token"},
+	})))
+
+	p, err := r.New(ctx, "oidc", map[string]string{"issuer_url": issuer})
+	require.NoError(t, err)
+
+	ops := p.Private("foo", "bar")
+	oidcOps, ok := ops.(provider.OIDCOperations)
+	require.True(t, ok)
+
+	initial := &oauth2.Token{RefreshToken: "initial-refresh"}
+	initial = initial.WithExtra(map[string]interface{}{"id_token": idToken})
+
+	claims, err := oidcOps.VerifyIDToken(ctx, initial, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "user-1", claims.Subject)
+
+	refreshed, err := ops.RefreshToken(ctx, initial)
+	require.NoError(t, err)
+	require.Empty(t, refreshed.Extra("id_token"))
+
+	preserved, err := oidcOps.VerifyIDToken(ctx, refreshed, "", claims)
+	require.NoError(t, err)
+	require.Same(t, claims, preserved)
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// PublicOperations are the operations available to a provider without
+// knowledge of a client secret, suitable for driving the browser-facing half
+// of an authorization code flow.
+type PublicOperations interface {
+	// AuthCodeURL constructs the URL the user should be redirected to in
+	// order to begin an authorization code flow. It returns false if the
+	// provider does not support this operation.
+	AuthCodeURL(state string, opts ...AuthCodeURLOption) (string, bool)
+
+	// DeviceAuthorization initiates an RFC 8628 device authorization grant.
+	// It returns ErrDeviceAuthorizationUnsupported if the provider has no
+	// device authorization endpoint.
+	DeviceAuthorization(ctx context.Context, opts ...AuthCodeURLOption) (*DeviceAuthorization, error)
+}
+
+// PrivateOperations are the operations available to a provider once a client
+// secret is known.
+type PrivateOperations interface {
+	PublicOperations
+
+	// AuthCodeExchange redeems an authorization code for a token.
+	AuthCodeExchange(ctx context.Context, code string, opts ...TokenRequestOption) (*oauth2.Token, error)
+
+	// RefreshToken exchanges a refresh token for a new access token.
+	RefreshToken(ctx context.Context, token *oauth2.Token, opts ...TokenRequestOption) (*oauth2.Token, error)
+
+	// ClientCredentials performs the client credentials grant.
+	ClientCredentials(ctx context.Context, opts ...TokenRequestOption) (*oauth2.Token, error)
+
+	// TokenExchange performs an RFC 8693 token exchange.
+	TokenExchange(ctx context.Context, params TokenExchangeParams) (*oauth2.Token, error)
+
+	// DevicePollToken polls the token endpoint for the result of a device
+	// authorization grant initiated with DeviceAuthorization.
+	DevicePollToken(ctx context.Context, deviceCode string, interval time.Duration) (*oauth2.Token, error)
+}
+
+// operations is the default implementation of PrivateOperations, shared by
+// every built-in provider. It is parameterized by an endpointResolver so
+// that providers whose endpoints vary per request (e.g. multi-tenant Azure
+// AD) can still reuse the grant plumbing below, and by a ClientAuthenticator
+// so that the client authentication method can vary independently of the
+// grant being performed.
+type operations struct {
+	resolver      endpointResolver
+	clientID      string
+	clientSecret  string
+	authenticator ClientAuthenticator
+}
+
+// endpointResolver produces the Endpoint to use for a given set of
+// provider-specific options supplied at call time.
+type endpointResolver interface {
+	resolveEndpoint(providerOptions map[string]string) Endpoint
+}
+
+type staticEndpointResolver Endpoint
+
+func (r staticEndpointResolver) resolveEndpoint(map[string]string) Endpoint {
+	return Endpoint(r)
+}
+
+// auth returns the ClientAuthenticator to use for token requests, defaulting
+// to client_secret_post, this package's long-standing behavior, when none
+// was configured.
+func (o *operations) auth() ClientAuthenticator {
+	if o.authenticator != nil {
+		return o.authenticator
+	}
+	return ClientSecretPostAuthenticator(o.clientID, o.clientSecret)
+}
+
+func (o *operations) AuthCodeURL(state string, opts ...AuthCodeURLOption) (string, bool) {
+	c := newAuthCodeURLConfig(opts)
+
+	ep := o.resolver.resolveEndpoint(c.providerOptions)
+
+	if c.par {
+		// Pushing to a PAR endpoint requires network I/O this method's
+		// synchronous, context-less signature cannot perform. Callers that
+		// set WithPAR(true) must use PushedAuthorizationRequestURL instead.
+		return "", false
+	}
+
+	if c.jarSigner != nil {
+		authCodeURL, err := jarAuthCodeURL(ep.AuthURL, o.clientID, state, c)
+		if err != nil {
+			return "", false
+		}
+		return authCodeURL, true
+	}
+
+	var authCodeOpts []oauth2.AuthCodeOption
+	for k, v := range c.urlParams {
+		authCodeOpts = append(authCodeOpts, oauth2.SetAuthURLParam(k, v[0]))
+	}
+
+	cfg := &oauth2.Config{
+		ClientID:    o.clientID,
+		Endpoint:    ep.Endpoint,
+		RedirectURL: c.redirectURL,
+		Scopes:      c.scopes,
+	}
+
+	return cfg.AuthCodeURL(state, authCodeOpts...), true
+}
+
+func (o *operations) AuthCodeExchange(ctx context.Context, code string, opts ...TokenRequestOption) (*oauth2.Token, error) {
+	c := newTokenRequestConfig(opts)
+
+	v := url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {code},
+	}
+	if c.redirectURL != "" {
+		v.Set("redirect_uri", c.redirectURL)
+	}
+	for k, vals := range c.urlParams {
+		v.Set(k, vals[0])
+	}
+
+	ep := o.resolver.resolveEndpoint(nil)
+	return retrieveToken(ctx, o.auth(), ep.TokenURL, v, c.dpopSigner)
+}
+
+func (o *operations) RefreshToken(ctx context.Context, token *oauth2.Token, opts ...TokenRequestOption) (*oauth2.Token, error) {
+	c := newTokenRequestConfig(opts)
+
+	v := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+	}
+	for k, vals := range c.urlParams {
+		v.Set(k, vals[0])
+	}
+
+	ep := o.resolver.resolveEndpoint(nil)
+	refreshed, err := retrieveToken(ctx, o.auth(), ep.TokenURL, v, c.dpopSigner)
+	if err != nil {
+		return nil, err
+	}
+
+	// Providers are not required to rotate the refresh token on every use;
+	// when they don't return a new one, keep the one the caller gave us.
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+
+	return refreshed, nil
+}
+
+func (o *operations) ClientCredentials(ctx context.Context, opts ...TokenRequestOption) (*oauth2.Token, error) {
+	c := newTokenRequestConfig(opts)
+
+	v := url.Values{"grant_type": {"client_credentials"}}
+	for k, vals := range c.urlParams {
+		v.Set(k, vals[0])
+	}
+
+	ep := o.resolver.resolveEndpoint(nil)
+	return retrieveToken(ctx, o.auth(), ep.TokenURL, v, c.dpopSigner)
+}
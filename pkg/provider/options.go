@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"crypto"
+	"net/url"
+)
+
+// AuthCodeURLOption mutates the URL values used to build an authorization
+// code URL. Options are applied in the order given to AuthCodeURL.
+type AuthCodeURLOption interface {
+	applyAuthCodeURL(c *authCodeURLConfig)
+}
+
+// TokenRequestOption mutates the configuration used when a provider makes a
+// token request (authorization code exchange, refresh, or client
+// credentials).
+type TokenRequestOption interface {
+	applyTokenRequest(c *tokenRequestConfig)
+}
+
+type authCodeURLConfig struct {
+	redirectURL     string
+	scopes          []string
+	urlParams       url.Values
+	providerOptions map[string]string
+	jarSigner       JWTSigner
+	par             bool
+}
+
+type tokenRequestConfig struct {
+	redirectURL string
+	urlParams   url.Values
+	dpopSigner  crypto.Signer
+}
+
+// WithRedirectURL sets the redirect_uri parameter of a request.
+type WithRedirectURL string
+
+func (o WithRedirectURL) applyAuthCodeURL(c *authCodeURLConfig)   { c.redirectURL = string(o) }
+func (o WithRedirectURL) applyTokenRequest(c *tokenRequestConfig) { c.redirectURL = string(o) }
+
+// WithScopes sets the scopes requested of the provider.
+type WithScopes []string
+
+func (o WithScopes) applyAuthCodeURL(c *authCodeURLConfig) { c.scopes = append(c.scopes, o...) }
+
+// WithURLParams sets additional, provider-specific query parameters on the
+// outgoing request.
+type WithURLParams map[string]string
+
+func (o WithURLParams) applyAuthCodeURL(c *authCodeURLConfig) {
+	if c.urlParams == nil {
+		c.urlParams = make(url.Values, len(o))
+	}
+	for k, v := range o {
+		c.urlParams.Set(k, v)
+	}
+}
+
+func (o WithURLParams) applyTokenRequest(c *tokenRequestConfig) {
+	if c.urlParams == nil {
+		c.urlParams = make(url.Values, len(o))
+	}
+	for k, v := range o {
+		c.urlParams.Set(k, v)
+	}
+}
+
+// WithProviderOptions supplies the provider-specific options that were given
+// at authorization-code-URL time (e.g. a tenant override) for providers that
+// support per-request overrides of their factory configuration.
+type WithProviderOptions map[string]string
+
+func (o WithProviderOptions) applyAuthCodeURL(c *authCodeURLConfig) {
+	if c.providerOptions == nil {
+		c.providerOptions = make(map[string]string, len(o))
+	}
+	for k, v := range o {
+		c.providerOptions[k] = v
+	}
+}
+
+func newAuthCodeURLConfig(opts []AuthCodeURLOption) *authCodeURLConfig {
+	c := &authCodeURLConfig{}
+	for _, opt := range opts {
+		opt.applyAuthCodeURL(c)
+	}
+	return c
+}
+
+func newTokenRequestConfig(opts []TokenRequestOption) *tokenRequestConfig {
+	c := &tokenRequestConfig{}
+	for _, opt := range opts {
+		opt.applyTokenRequest(c)
+	}
+	return c
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// WithPAR toggles whether an authorization request's parameters (or, if
+// combined with WithJARSigner, its signed JAR request object) are pushed to
+// the provider's pushed authorization request endpoint (RFC 9126) rather
+// than sent directly as part of the authorization URL.
+//
+// AuthCodeURL cannot service a request with WithPAR(true) itself: pushing
+// requires network I/O that its synchronous, context-less signature has no
+// way to perform, so it returns false in that case. Use
+// PushedAuthorizationRequestURL instead, obtained via a type assertion on
+// PARCapableOperations.
+func WithPAR(enabled bool) AuthCodeURLOption { return parOption(enabled) }
+
+type parOption bool
+
+func (o parOption) applyAuthCodeURL(c *authCodeURLConfig) { c.par = bool(o) }
+
+// ErrPARUnsupported is returned by PushedAuthorizationRequestURL when the
+// provider has no pushed authorization request endpoint configured.
+var ErrPARUnsupported = errors.New("provider: pushed authorization requests are not supported by this provider")
+
+// PARCapableOperations is implemented by the operations of a provider whose
+// endpoint includes a pushed authorization request endpoint. Callers obtain
+// it with a type assertion on the PublicOperations or PrivateOperations
+// returned by a Provider:
+//
+//	if par, ok := ops.(provider.PARCapableOperations); ok {
+//	    authCodeURL, err := par.PushedAuthorizationRequestURL(ctx, state, opts...)
+//	}
+type PARCapableOperations interface {
+	// PushedAuthorizationRequestURL pushes an authorization request's
+	// parameters (wrapped in a signed JAR request object first, if
+	// WithJARSigner is also given) to the provider's pushed authorization
+	// request endpoint, and returns an authorization URL containing only
+	// client_id and the request_uri the provider returned. It returns
+	// ErrPARUnsupported if the provider has no PAR endpoint.
+	PushedAuthorizationRequestURL(ctx context.Context, state string, opts ...AuthCodeURLOption) (string, error)
+}
+
+func (o *operations) PushedAuthorizationRequestURL(ctx context.Context, state string, opts ...AuthCodeURLOption) (string, error) {
+	c := newAuthCodeURLConfig(opts)
+
+	ep := o.resolver.resolveEndpoint(c.providerOptions)
+	if ep.PushedAuthorizationRequestURL == "" {
+		return "", ErrPARUnsupported
+	}
+
+	var v url.Values
+	if c.jarSigner != nil {
+		requestJWT, err := signJWT(c.jarSigner, jarClaims(o.clientID, state, c))
+		if err != nil {
+			return "", fmt.Errorf("provider: signing JAR request object: %w", err)
+		}
+		v = url.Values{"client_id": {o.clientID}, "request": {requestJWT}}
+	} else {
+		v = authCodeURLValues(o.clientID, state, c)
+	}
+
+	values, status, _, err := postForm(ctx, o.auth(), ep.PushedAuthorizationRequestURL, v, "")
+	if err != nil {
+		return "", err
+	}
+	if status < 200 || status > 299 {
+		return "", fmt.Errorf(
+			"provider: pushed authorization request to %s returned status %d: error=%q error_description=%q",
+			ep.PushedAuthorizationRequestURL, status, values.Get("error"), values.Get("error_description"),
+		)
+	}
+
+	requestURI := values.Get("request_uri")
+	if requestURI == "" {
+		return "", fmt.Errorf("provider: pushed authorization request response did not include a request_uri")
+	}
+
+	u, err := url.Parse(ep.AuthURL)
+	if err != nil {
+		return "", fmt.Errorf("provider: parsing authorization URL: %w", err)
+	}
+	u.RawQuery = url.Values{"client_id": {o.clientID}, "request_uri": {requestURI}}.Encode()
+
+	return u.String(), nil
+}
@@ -0,0 +1,82 @@
+package provider_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasicPublicPKCE(t *testing.T) {
+	ctx := context.Background()
+
+	r := provider.NewRegistry()
+	r.MustRegister("basic", basicTestFactory)
+
+	basicTest, err := r.New(ctx, "basic", map[string]string{})
+	require.NoError(t, err)
+
+	ops := basicTest.Public("foo")
+
+	verifier, err := provider.GeneratePKCEVerifier()
+	require.NoError(t, err)
+	require.Len(t, verifier, 64)
+
+	challenge, err := provider.NewPKCEChallenge(verifier, "")
+	require.NoError(t, err)
+
+	authCodeURL, ok := ops.AuthCodeURL(
+		"state",
+		provider.WithRedirectURL("http://example.com/redirect"),
+		challenge,
+	)
+	require.True(t, ok)
+
+	u, err := url.Parse(authCodeURL)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte(verifier))
+	expectedChallenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	qs := u.Query()
+	assert.Equal(t, expectedChallenge, qs.Get("code_challenge"))
+	assert.Equal(t, "S256", qs.Get("code_challenge_method"))
+}
+
+func TestBasicPublicPKCEPlain(t *testing.T) {
+	ctx := context.Background()
+
+	r := provider.NewRegistry()
+	r.MustRegister("basic", basicTestFactory)
+
+	basicTest, err := r.New(ctx, "basic", map[string]string{})
+	require.NoError(t, err)
+
+	ops := basicTest.Public("foo")
+
+	challenge, err := provider.NewPKCEChallenge("plain-verifier", provider.PKCEMethodPlain)
+	require.NoError(t, err)
+
+	authCodeURL, ok := ops.AuthCodeURL(
+		"state",
+		challenge,
+	)
+	require.True(t, ok)
+
+	u, err := url.Parse(authCodeURL)
+	require.NoError(t, err)
+
+	qs := u.Query()
+	assert.Equal(t, "plain-verifier", qs.Get("code_challenge"))
+	assert.Equal(t, "plain", qs.Get("code_challenge_method"))
+}
+
+func TestNewPKCEChallengeUnsupportedMethod(t *testing.T) {
+	_, err := provider.NewPKCEChallenge("some-verifier", "foo")
+	require.EqualError(t, err, `provider: unsupported PKCE method "foo"`)
+}
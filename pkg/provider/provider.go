@@ -0,0 +1,42 @@
+package provider
+
+import "context"
+
+// Provider is a configured OAuth 2.0 provider capable of producing the
+// operations available to public (browser) and private (server) clients.
+type Provider interface {
+	// Public returns the operations available to a client that is not
+	// trusted with a client secret.
+	Public(clientID string) PublicOperations
+
+	// Private returns the operations available to a client that holds a
+	// client secret.
+	Private(clientID, clientSecret string) PrivateOperations
+}
+
+// Factory constructs a Provider from the plugin-level options an operator
+// configured for this mount (e.g. a tenant or issuer URL).
+type Factory func(ctx context.Context, options map[string]string) (Provider, error)
+
+type baseProvider struct {
+	resolver endpointResolver
+
+	// authenticatorFactory builds the ClientAuthenticator for a credential's
+	// client ID/secret. It is never nil: factories that construct a
+	// baseProvider default it to client_secret_post when the operator does
+	// not configure auth_style.
+	authenticatorFactory clientAuthenticatorFactory
+}
+
+func (p *baseProvider) Public(clientID string) PublicOperations {
+	return &operations{resolver: p.resolver, clientID: clientID}
+}
+
+func (p *baseProvider) Private(clientID, clientSecret string) PrivateOperations {
+	return &operations{
+		resolver:      p.resolver,
+		clientID:      clientID,
+		clientSecret:  clientSecret,
+		authenticator: p.authenticatorFactory(clientID, clientSecret),
+	}
+}
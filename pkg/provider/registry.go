@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Registry holds the set of provider factories known to the plugin, keyed by
+// the name operators use in the `provider` field of their mount
+// configuration.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register associates a name with a Factory. It returns an error if the name
+// is already registered.
+func (r *Registry) Register(name string, factory Factory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, found := r.factories[name]; found {
+		return fmt.Errorf("provider: name %q is already registered", name)
+	}
+
+	r.factories[name] = factory
+	return nil
+}
+
+// MustRegister is like Register but panics if the name is already
+// registered. It is intended for use in package init functions and tests.
+func (r *Registry) MustRegister(name string, factory Factory) {
+	if err := r.Register(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// New looks up the factory registered under name and invokes it with the
+// given options.
+func (r *Registry) New(ctx context.Context, name string, options map[string]string) (Provider, error) {
+	r.mu.RLock()
+	factory, found := r.factories[name]
+	r.mu.RUnlock()
+
+	if !found {
+		return nil, fmt.Errorf("provider: no such provider %q", name)
+	}
+
+	return factory(ctx, options)
+}
+
+// GlobalRegistry is the registry populated with this module's built-in
+// providers.
+var GlobalRegistry = NewRegistry()
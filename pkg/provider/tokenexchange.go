@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+// Standard token type identifiers defined by RFC 8693 section 3.
+const (
+	TokenTypeAccessToken  = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeRefreshToken = "urn:ietf:params:oauth:token-type:refresh_token"
+	TokenTypeIDToken      = "urn:ietf:params:oauth:token-type:id_token"
+	TokenTypeSAML1        = "urn:ietf:params:oauth:token-type:saml1"
+	TokenTypeSAML2        = "urn:ietf:params:oauth:token-type:saml2"
+	TokenTypeJWT          = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+const grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+// TokenExchangeParams are the parameters of an RFC 8693 token exchange
+// request. SubjectToken and SubjectTokenType are required; the rest are
+// optional per the spec.
+type TokenExchangeParams struct {
+	SubjectToken     string
+	SubjectTokenType string
+
+	ActorToken     string
+	ActorTokenType string
+
+	Audience           string
+	Resource           string
+	Scope              []string
+	RequestedTokenType string
+}
+
+func (p TokenExchangeParams) values() url.Values {
+	v := url.Values{
+		"grant_type":         {grantTypeTokenExchange},
+		"subject_token":      {p.SubjectToken},
+		"subject_token_type": {p.SubjectTokenType},
+	}
+
+	if p.ActorToken != "" {
+		v.Set("actor_token", p.ActorToken)
+		v.Set("actor_token_type", p.ActorTokenType)
+	}
+	if p.Audience != "" {
+		v.Set("audience", p.Audience)
+	}
+	if p.Resource != "" {
+		v.Set("resource", p.Resource)
+	}
+	if len(p.Scope) > 0 {
+		v.Set("scope", joinScopes(p.Scope))
+	}
+	if p.RequestedTokenType != "" {
+		v.Set("requested_token_type", p.RequestedTokenType)
+	}
+
+	return v
+}
+
+func joinScopes(scopes []string) string {
+	joined := ""
+	for i, s := range scopes {
+		if i > 0 {
+			joined += " "
+		}
+		joined += s
+	}
+	return joined
+}
+
+// TokenExchange performs an RFC 8693 OAuth 2.0 token exchange, trading the
+// subject (and optional actor) token for a new token. The response's
+// issued_token_type and, if present, refresh_token are preserved on the
+// returned token's extra parameters and fields respectively, so that
+// callers storing the result as a new credential can tell what kind of
+// token they now hold.
+func (o *operations) TokenExchange(ctx context.Context, params TokenExchangeParams) (*oauth2.Token, error) {
+	ep := o.resolver.resolveEndpoint(nil)
+	return retrieveToken(ctx, o.auth(), ep.TokenURL, params.values(), nil)
+}
@@ -0,0 +1,57 @@
+package provider_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/provider"
+	"github.com/puppetlabs/vault-plugin-secrets-oauthapp/v2/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestBasicTokenExchange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r := provider.NewRegistry()
+	r.MustRegister("basic", basicTestFactory)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		data, err := url.ParseQuery(string(b))
+		require.NoError(t, err)
+
+		assert.Equal(t, "foo", data.Get("client_id"))
+		assert.Equal(t, "bar", data.Get("client_secret"))
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:token-exchange", data.Get("grant_type"))
+		assert.Equal(t, "subject-token", data.Get("subject_token"))
+		assert.Equal(t, provider.TokenTypeAccessToken, data.Get("subject_token_type"))
+		assert.Equal(t, "https://downstream.example.com", data.Get("audience"))
+
+		_, _ = w.Write([]byte(`access_token=exchanged&issued_token_type=urn:ietf:params:oauth:token-type:access_token&token_type=bearer&expires_in=60`))
+	})
+	c := &http.Client{Transport: &testutil.MockRoundTripper{Handler: h}}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c)
+
+	basicTest, err := r.New(ctx, "basic", map[string]string{})
+	require.NoError(t, err)
+
+	ops := basicTest.Private("foo", "bar")
+
+	token, err := ops.TokenExchange(ctx, provider.TokenExchangeParams{
+		SubjectToken:     "subject-token",
+		SubjectTokenType: provider.TokenTypeAccessToken,
+		Audience:         "https://downstream.example.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "exchanged", token.AccessToken)
+	assert.Equal(t, provider.TokenTypeAccessToken, token.Extra("issued_token_type"))
+}
@@ -0,0 +1,21 @@
+// Package testutil provides small helpers shared across the test suites of
+// this module's packages.
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// MockRoundTripper dispatches requests directly to an in-process handler,
+// allowing tests to exercise OAuth 2.0 client code paths without opening a
+// real network listener.
+type MockRoundTripper struct {
+	Handler http.Handler
+}
+
+func (rt *MockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	rt.Handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}